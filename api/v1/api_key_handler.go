@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"toko-buku-api/internal/apikeys"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/web"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Handler for API key endpoints
+
+type APIKeyHandler struct {
+	Usecase apikeys.Usecase
+	Log     *logger.Logger
+}
+
+func NewAPIKeyHandler(usecase apikeys.Usecase, logger *logger.Logger, validate *validator.Validate) *APIKeyHandler {
+	return &APIKeyHandler{
+		Usecase: usecase,
+		Log:     logger,
+	}
+}
+
+func (h APIKeyHandler) GetAPIKeys(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+
+	apiKeys, err := h.Usecase.GetAPIKeys(ctx)
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(apiKeys)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h APIKeyHandler) CreateAPIKey(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.CreateAPIKey"
+
+	createRequestAPIKey := new(apikeys.CreateAPIKeyRequest)
+	err := decodeJSON(request, createRequestAPIKey)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse create api key with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	claims, _ := web.GetClaims(ctx)
+	createRequestAPIKey.Created_By = claims.Subject
+
+	apiKey, err := h.Usecase.CreateAPIKey(ctx, claims, createRequestAPIKey)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to create api key with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(apiKey)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h APIKeyHandler) RevokeAPIKey(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.RevokeAPIKey"
+
+	apiKeyById := request.PathValue("apiKeyById")
+	id, err := strconv.ParseUint(apiKeyById, 10, 32)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive revoke api key by id: %+v with error", apiKeyById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	apiKey, err := h.Usecase.RevokeAPIKey(ctx, uint32(id))
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(apiKey)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"net/http"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Handler for auth-related endpoints
+
+type AuthHandler struct {
+	Credentials auth.Credentials
+	Tokens      *auth.JWT
+	Log         *logger.Logger
+	Validate    *validator.Validate
+}
+
+func NewAuthHandler(credentials auth.Credentials, tokens *auth.JWT, logger *logger.Logger, validate *validator.Validate) *AuthHandler {
+	return &AuthHandler{
+		Credentials: credentials,
+		Tokens:      tokens,
+		Log:         logger,
+		Validate:    validate,
+	}
+}
+
+func (h AuthHandler) CreateToken(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.CreateToken"
+
+	tokenRequest := new(auth.TokenRequest)
+	err := decodeJSON(request, tokenRequest)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse create token with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	err = h.Validate.Struct(tokenRequest)
+	if err != nil {
+		respondWithError(writer, request, errs.Invalid(err))
+		return
+	}
+
+	claims, err := h.Credentials.Authenticate(ctx, tokenRequest.Username, tokenRequest.Password)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to authenticate user", "username", tokenRequest.Username, "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	token, err := h.Tokens.Sign(claims)
+	if err != nil {
+		h.Log.Error(ctx, "failed to sign token", "error", err, "func_name", funcName)
+		respondWithError(writer, request, errs.Internal(err))
+		return
+	}
+
+	response := utils.StatusOK(auth.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.Tokens.TTL().Seconds()),
+	})
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
@@ -1,12 +1,12 @@
 package v1
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"toko-buku-api/internal/authors"
 	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
 	"toko-buku-api/utils"
 
 	"github.com/go-playground/validator/v10"
@@ -17,6 +17,8 @@ import (
 type AuthorHandler struct {
 	Usecase authors.Usecase
 	Log     *logger.Logger
+	// RequireIfMatch answers writes without If-Match with 428
+	RequireIfMatch bool
 }
 
 func NewAuthorHandler(usercase authors.Usecase, logger *logger.Logger, validate *validator.Validate) *AuthorHandler {
@@ -38,20 +40,22 @@ func NewAuthorHandler(usercase authors.Usecase, logger *logger.Logger, validate
 func (h AuthorHandler) GetAuthors(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	funcName := "handler.GetAuthors"
-	h.Log.Info(ctx, "receive get authors request", "func_name", funcName)
 
-	authors, err := h.Usecase.GetAuthors(ctx)
+	options, err := query.Parse(request.URL.Query(), &authors.QuerySpec)
 	if err != nil {
-		h.Log.Warn(ctx, "receive get authors with error request", "error", err, "func_name", funcName)
+		h.Log.Warn(ctx, "receive get authors with invalid query", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
-		responseErr := utils.StatusBadRequest()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+	authors, page, err := h.Usecase.GetAuthors(ctx, options)
+	if err != nil {
+		h.Log.Warn(ctx, "receive get authors with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
 
-	h.Log.Info(ctx, "receive response to get authors response", "response", "waiting", "func_name", funcName)
-	response := utils.StatusOK(authors)
-	h.Log.Info(ctx, "receive response to get authors response", "response", "ok", "func_name", funcName)
+	response := utils.StatusOKWithPage(authors, newPageModel(request, page))
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
@@ -60,29 +64,30 @@ func (h AuthorHandler) GetAuthorById(writer http.ResponseWriter, request *http.R
 	funcName := "handler.GetAuthorById"
 
 	authorById := request.PathValue("authorById")
-	h.Log.Info(ctx, fmt.Sprintf("receive get author by id: %+v", authorById), "func_name", funcName)
-
-	id, err := strconv.Atoi(authorById)
+	id, err := strconv.ParseUint(authorById, 10, 16)
 	if err != nil {
-		h.Log.Error(ctx, fmt.Sprintf("receive get author by id: %+v with error", authorById), "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusInternalServerError()
-		utils.RespondErrorWithJSON(writer, http.StatusInternalServerError, responseErr)
+		h.Log.Warn(ctx, fmt.Sprintf("receive get author by id: %+v with error", authorById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
 		return
 	}
 
-	h.Log.Info(ctx, fmt.Sprintf("receive get author by id: %+v", id), "func_name", funcName)
 	author, err := h.Usecase.GetAuthorById(ctx, uint16(id))
 	if err != nil {
 		h.Log.Warn(ctx, "failed to parse request body", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
-		responseErr := utils.StatusNotFound()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+	if err := setETag(writer, author); err != nil {
+		h.Log.Error(ctx, "failed to compute the author etag", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	if notModified(writer, request) {
 		return
 	}
 
 	response := utils.StatusOK(author)
-	h.Log.Info(ctx, fmt.Sprintf("receive response to get author by id: %+v", id), "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
@@ -90,29 +95,23 @@ func (h AuthorHandler) GetAuthorById(writer http.ResponseWriter, request *http.R
 func (h AuthorHandler) CreateAuthor(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	funcName := "handler.CreateAuthor"
-	h.Log.Info(ctx, "receive request to create author", "func_name", funcName)
 
 	createRequestAuthor := new(authors.CreateAuthorRequest)
-	err := json.NewDecoder(request.Body).Decode(&createRequestAuthor)
+	err := decodeJSON(request, createRequestAuthor)
 	if err != nil {
 		h.Log.Error(ctx, "failed to parse create author with error request", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 
 	createAuthor, err := h.Usecase.CreateAuthor(ctx, createRequestAuthor)
 	if err != nil {
 		h.Log.Warn(ctx, "invalid to parse create author with error request", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 
 	response := utils.StatusOK(createAuthor)
-	h.Log.Info(ctx, "receive response to create author", "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
@@ -121,66 +120,70 @@ func (h AuthorHandler) UpdateAuthor(writer http.ResponseWriter, request *http.Re
 	funcName := "handler.UpdateAuthor"
 
 	authorById := request.PathValue("authorById")
-	h.Log.Debug(ctx, fmt.Sprintf("receive request to update author: %+v", authorById), "func_name", funcName)
-
-	id, err := strconv.Atoi(authorById)
+	id, err := strconv.ParseUint(authorById, 10, 16)
 	if err != nil {
 		h.Log.Warn(ctx, fmt.Sprintf("receive update author by id: %+v with error", authorById), "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusInternalServerError()
-		utils.RespondErrorWithJSON(writer, http.StatusInternalServerError, responseErr)
+		respondWithError(writer, request, invalidID(err))
 		return
 	}
 
 	updateRequestAuthor := new(authors.UpdateAuthorRequest)
-	err = json.NewDecoder(request.Body).Decode(&updateRequestAuthor)
+	err = decodeJSON(request, updateRequestAuthor)
 	if err != nil {
 		h.Log.Debug(ctx, "failed to parse update author with error request", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 	updateRequestAuthor.ID = uint16(id)
+	updateRequestAuthor.If_Match, err = ifMatch(request, h.RequireIfMatch)
+	if err != nil {
+		h.Log.Warn(ctx, "receive update author without if-match", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
 	authorResponse, err := h.Usecase.UpdateAuthor(ctx, updateRequestAuthor)
 	if err != nil {
 		h.Log.Warn(ctx, "invalid to parse update author with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
-		responseErr := utils.StatusBadRequest()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+	if err := setETag(writer, authorResponse); err != nil {
+		h.Log.Error(ctx, "failed to compute the author etag", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
 
 	response := utils.StatusOK(authorResponse)
-	h.Log.Info(ctx, fmt.Sprintf("receive response to update author: %+v", id), "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
 func (h AuthorHandler) DeleteAuthor(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	funcName := "handler.DeleteAuthor"
-	h.Log.Debug(ctx, "receive request to delete author", "func_name", funcName)
 	authorById := request.PathValue("authorById")
-	id, err := strconv.Atoi(authorById)
+	id, err := strconv.ParseUint(authorById, 10, 16)
 	if err != nil {
-		h.Log.Error(ctx, fmt.Sprintf("receive get delete author by id: %+v with error", authorById), "error", err, "func_name", funcName)
+		h.Log.Warn(ctx, fmt.Sprintf("receive get delete author by id: %+v with error", authorById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
 
-		responseErr := utils.StatusInternalServerError()
-		utils.RespondErrorWithJSON(writer, http.StatusInternalServerError, responseErr)
+	ifMatchHeader, err := ifMatch(request, h.RequireIfMatch)
+	if err != nil {
+		h.Log.Warn(ctx, "receive delete author without if-match", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
 
-	err = h.Usecase.DeleteAuthor(ctx, uint16(id))
+	err = h.Usecase.DeleteAuthor(ctx, uint16(id), ifMatchHeader)
 	if err != nil {
 		h.Log.Warn(ctx, "failed to parse request body", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest()
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 
 	response := utils.StatusOK(struct{}{})
-	h.Log.Info(ctx, fmt.Sprintf("receive response to delete author: %+v", id), "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
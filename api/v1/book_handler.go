@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"toko-buku-api/internal/books"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Handler for book-related endpoints
+
+type BookHandler struct {
+	Usecase books.Usecase
+	Log     *logger.Logger
+}
+
+func NewBookHandler(usecase books.Usecase, logger *logger.Logger, validate *validator.Validate) *BookHandler {
+	return &BookHandler{
+		Usecase: usecase,
+		Log:     logger,
+	}
+}
+
+func (h BookHandler) GetBooks(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.GetBooks"
+
+	options, err := query.Parse(request.URL.Query(), &books.QuerySpec)
+	if err != nil {
+		h.Log.Warn(ctx, "receive get books with invalid query", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	books, page, err := h.Usecase.GetBooks(ctx, options)
+	if err != nil {
+		h.Log.Warn(ctx, "receive get books with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOKWithPage(books, newPageModel(request, page))
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h BookHandler) GetBookById(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.GetBookById"
+
+	bookById := request.PathValue("bookById")
+	id, err := strconv.ParseUint(bookById, 10, 32)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive get book by id: %+v with error", bookById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	book, err := h.Usecase.GetBookById(ctx, uint32(id))
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(book)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h BookHandler) CreateBook(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.CreateBook"
+
+	createRequestBook := new(books.CreateBookRequest)
+	err := decodeJSON(request, createRequestBook)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse create book with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	createBook, err := h.Usecase.CreateBook(ctx, createRequestBook)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to parse create book with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(createBook)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h BookHandler) UpdateBook(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.UpdateBook"
+
+	bookById := request.PathValue("bookById")
+	id, err := strconv.ParseUint(bookById, 10, 32)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive update book by id: %+v with error", bookById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	updateRequestBook := new(books.UpdateBookRequest)
+	err = decodeJSON(request, updateRequestBook)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse update book with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	updateRequestBook.ID = uint32(id)
+
+	bookResponse, err := h.Usecase.UpdateBook(ctx, updateRequestBook)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to parse update book with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(bookResponse)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h BookHandler) DeleteBook(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.DeleteBook"
+
+	bookById := request.PathValue("bookById")
+	id, err := strconv.ParseUint(bookById, 10, 32)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive delete book by id: %+v with error", bookById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	err = h.Usecase.DeleteBook(ctx, uint32(id))
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(struct{}{})
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
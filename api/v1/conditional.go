@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/etag"
+)
+
+// Headers of the conditional requests, RFC 9110
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// setETag sets the ETag header of the response to the entity tag of v
+func setETag(writer http.ResponseWriter, v any) error {
+	tag, err := etag.Of(v)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set(headerETag, tag)
+	return nil
+}
+
+// notModified answers 304 when the If-None-Match header of the request
+// matches the ETag set by setETag, reporting whether it did
+func notModified(writer http.ResponseWriter, request *http.Request) bool {
+	ifNoneMatch := strings.Join(request.Header.Values(headerIfNoneMatch), ",")
+	if ifNoneMatch == "" || !etag.Match(ifNoneMatch, writer.Header().Get(headerETag), true) {
+		return false
+	}
+
+	writer.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// ifMatch returns the If-Match header of the request, failing when required
+// and missing
+func ifMatch(request *http.Request, required bool) (string, error) {
+	value := strings.Join(request.Header.Values(headerIfMatch), ",")
+	if value == "" && required {
+		return "", errs.PreconditionRequired(headerIfMatch + " header is required")
+	}
+
+	return value, nil
+}
@@ -1,12 +1,12 @@
 package v1
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"toko-buku-api/internal/countries"
 	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
 	"toko-buku-api/utils"
 
 	"github.com/go-playground/validator/v10"
@@ -17,6 +17,8 @@ import (
 type CountryHandler struct {
 	Usecase countries.Usecase
 	Log     *logger.Logger
+	// RequireIfMatch answers writes without If-Match with 428
+	RequireIfMatch bool
 }
 
 func NewCountryHandler(usercase countries.Usecase, logger *logger.Logger, validate *validator.Validate) *CountryHandler {
@@ -29,20 +31,22 @@ func NewCountryHandler(usercase countries.Usecase, logger *logger.Logger, valida
 func (h CountryHandler) GetCountries(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	funcName := "handler.GetCountries"
-	h.Log.Info(ctx, "receive get countries request", "func_name", funcName)
 
-	countries, err := h.Usecase.GetCountries(ctx)
+	options, err := query.Parse(request.URL.Query(), &countries.QuerySpec)
 	if err != nil {
-		h.Log.Warn(ctx, "receive get countries with error request", "error", err, "func_name", funcName)
+		h.Log.Warn(ctx, "receive get countries with invalid query", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+	countries, page, err := h.Usecase.GetCountries(ctx, options)
+	if err != nil {
+		h.Log.Warn(ctx, "receive get countries with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
 
-	h.Log.Info(ctx, "receive response to get countries response", "response", "waiting", "func_name", funcName)
-	response := utils.StatusOK(countries)
-	h.Log.Info(ctx, "receive response to get authors response", "response", "ok", "func_name", funcName)
+	response := utils.StatusOKWithPage(countries, newPageModel(request, page))
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
@@ -51,57 +55,52 @@ func (h CountryHandler) GetCountryById(writer http.ResponseWriter, request *http
 	funcName := "handler.GetCountryById"
 
 	countryById := request.PathValue("countryById")
-	h.Log.Info(ctx, fmt.Sprintf("receive request to get country by id: %+v", countryById), "func_name", funcName)
-
-	id, err := strconv.Atoi(countryById)
+	id, err := strconv.ParseUint(countryById, 10, 16)
 	if err != nil {
-		h.Log.Error(ctx, fmt.Sprintf("receive get country by id: %+v with error", countryById), "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusInternalServerError(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusInternalServerError, responseErr)
+		h.Log.Warn(ctx, fmt.Sprintf("receive get country by id: %+v with error", countryById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
 		return
 	}
 
-	h.Log.Info(ctx, fmt.Sprintf("receive get country by id: %+v", id), "func_name", funcName)
 	country, err := h.Usecase.GetCountryByID(ctx, uint16(id))
 	if err != nil {
 		h.Log.Warn(ctx, "failed to parse request body", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+	if err := setETag(writer, country); err != nil {
+		h.Log.Error(ctx, "failed to compute the country etag", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	if notModified(writer, request) {
 		return
 	}
 
 	response := utils.StatusOK(country)
-	h.Log.Info(ctx, fmt.Sprintf("receive response to get country by id: %+v", id), "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
 func (h CountryHandler) CreateCountry(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	funcName := "handler.CreateCountry"
-	h.Log.Info(ctx, "receive request to create country", "func_name", funcName)
 
 	createRequestAuthor := new(countries.CreateCountryRequest)
-	err := json.NewDecoder(request.Body).Decode(&createRequestAuthor)
+	err := decodeJSON(request, createRequestAuthor)
 	if err != nil {
 		h.Log.Warn(ctx, "failed to parse create country with error request", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 
 	createAuthor, err := h.Usecase.CreateCountry(ctx, createRequestAuthor)
 	if err != nil {
-		h.Log.Warn(ctx, "invalid to parse create country with error request", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		h.Log.Warn(ctx, "invalid to parse create country with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
 	response := utils.StatusOK(createAuthor)
-	h.Log.Info(ctx, "receive response to create country", "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
@@ -110,38 +109,42 @@ func (h CountryHandler) UpdateCountry(writer http.ResponseWriter, request *http.
 	funcName := "handler.UpdateCountry"
 
 	countryById := request.PathValue("countryById")
-	h.Log.Info(ctx, fmt.Sprintf("receive request to update country by id: %+v", countryById), "func_name", funcName)
-
-	id, err := strconv.Atoi(countryById)
+	id, err := strconv.ParseUint(countryById, 10, 16)
 	if err != nil {
 		h.Log.Warn(ctx, fmt.Sprintf("receive update country by id: %+v with error", countryById), "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusInternalServerError(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusInternalServerError, responseErr)
+		respondWithError(writer, request, invalidID(err))
 		return
 	}
 
 	updateRequestAuthor := new(countries.UpdateCountryRequest)
-	err = json.NewDecoder(request.Body).Decode(&updateRequestAuthor)
+	err = decodeJSON(request, updateRequestAuthor)
 	if err != nil {
 		h.Log.Debug(ctx, "failed to parse update country with error request", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 	updateRequestAuthor.ID = uint8(id)
+	updateRequestAuthor.If_Match, err = ifMatch(request, h.RequireIfMatch)
+	if err != nil {
+		h.Log.Warn(ctx, "receive update country without if-match", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
 	countryResponse, err := h.Usecase.UpdateCountry(ctx, updateRequestAuthor)
 	if err != nil {
 		h.Log.Warn(ctx, "invalid to parse update country with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
 
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+	if err := setETag(writer, countryResponse); err != nil {
+		h.Log.Error(ctx, "failed to compute the country etag", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
+
 	response := utils.StatusOK(countryResponse)
-	h.Log.Info(ctx, "receive response to update country", "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
 
@@ -150,27 +153,27 @@ func (h CountryHandler) DeleteAuthor(writer http.ResponseWriter, request *http.R
 	funcName := "handler.DeleteCountry"
 
 	countryById := request.PathValue("countryById")
-	h.Log.Info(ctx, fmt.Sprintf("receive request to delete country by id: %+v", countryById), "func_name", funcName)
-
-	id, err := strconv.Atoi(countryById)
+	id, err := strconv.ParseUint(countryById, 10, 16)
 	if err != nil {
-		h.Log.Error(ctx, fmt.Sprintf("receive to delete country by id: %+v with error", countryById), "error", err, "func_name", funcName)
+		h.Log.Warn(ctx, fmt.Sprintf("receive to delete country by id: %+v with error", countryById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
 
-		responseErr := utils.StatusInternalServerError(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusInternalServerError, responseErr)
+	ifMatchHeader, err := ifMatch(request, h.RequireIfMatch)
+	if err != nil {
+		h.Log.Warn(ctx, "receive delete country without if-match", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
 		return
 	}
 
-	err = h.Usecase.DeleteCountry(ctx, uint16(id))
+	err = h.Usecase.DeleteCountry(ctx, uint16(id), ifMatchHeader)
 	if err != nil {
 		h.Log.Warn(ctx, "failed to parse request body", "error", err, "func_name", funcName)
-
-		responseErr := utils.StatusBadRequest(err.Error())
-		utils.RespondErrorWithJSON(writer, http.StatusBadRequest, responseErr)
+		respondWithError(writer, request, err)
 		return
 	}
 
 	response := utils.StatusOK(struct{}{})
-	h.Log.Info(ctx, fmt.Sprintf("receive response to delete country: %+v", id), "response", "ok", "func_name", funcName)
 	utils.RespondWithJSON(writer, http.StatusOK, response)
 }
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"toko-buku-api/pkg/errs"
+)
+
+// contentTypeJSON is the only content type of the request bodies read
+const contentTypeJSON = "application/json"
+
+// singleObject is the message of the bodies not made of one json object
+const singleObject = "request body must contain a single JSON object"
+
+// decodeJSON decodes the body of the request, a single json object without
+// unknown fields, into v, a pointer to a struct. The size of the body is
+// limited by web.LimitBody, the errors are classified for respondWithError
+func decodeJSON(request *http.Request, v any) error {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil || mediaType != contentTypeJSON {
+		return errs.UnsupportedMediaType(fmt.Sprintf("Content-Type must be %s", contentTypeJSON))
+	}
+
+	decoder := json.NewDecoder(request.Body)
+
+	// null, or any value but an object, decodes into a struct without error
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return decodeError(err)
+	}
+	if raw[0] != '{' {
+		return errs.BadRequest(singleObject)
+	}
+
+	object := json.NewDecoder(bytes.NewReader(raw))
+	object.DisallowUnknownFields()
+	if err := object.Decode(v); err != nil {
+		return decodeError(err)
+	}
+
+	// a second value, even valid, is trailing garbage
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+		return errs.Wrap(errs.KindBadRequest, singleObject, err)
+	}
+
+	return nil
+}
+
+// decodeError classifies an error of json.Decoder.Decode
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return errs.Wrap(errs.KindTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit), err)
+	case errors.Is(err, io.EOF):
+		return errs.Wrap(errs.KindBadRequest, "request body must not be empty", err)
+	case errors.As(err, &syntaxErr):
+		return errs.Wrap(errs.KindBadRequest, fmt.Sprintf("malformed request body at position %d", syntaxErr.Offset), err)
+	case errors.As(err, &typeErr):
+		return errs.Wrap(errs.KindBadRequest, fmt.Sprintf("invalid value for field %q: must be %s", typeErr.Field, typeErr.Type), err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for the unknown fields
+		return errs.Wrap(errs.KindBadRequest, strings.TrimPrefix(err.Error(), "json: "), err)
+	}
+
+	return malformedBody(err)
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"toko-buku-api/internal/authors"
+	"toko-buku-api/pkg/errs"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBytes    int64
+		wantKind    errs.Kind
+		wantMessage string
+	}{
+		{"valid", "application/json", `{"country_id":100,"author":"Tere Liye","city":"Jakarta"}`, 0, 0, ""},
+		{"charset", "application/json; charset=utf-8", `{"author":"Tere Liye"}` + "\n", 0, 0, ""},
+		{"no content type", "", `{"author":"Tere Liye"}`, 0, errs.KindUnsupportedMediaType, "Content-Type must be application/json"},
+		{"form", "application/x-www-form-urlencoded", `author=Tere+Liye`, 0, errs.KindUnsupportedMediaType, "Content-Type must be application/json"},
+		{"empty", "application/json", ``, 0, errs.KindBadRequest, "request body must not be empty"},
+		{"syntax", "application/json", `{"author":}`, 0, errs.KindBadRequest, "malformed request body at position 11"},
+		{"truncated", "application/json", `{"author":"Tere`, 0, errs.KindBadRequest, "malformed request body"},
+		{"unknown field", "application/json", `{"author":"Tere Liye","nickname":"Tere"}`, 0, errs.KindBadRequest, `unknown field "nickname"`},
+		{"type", "application/json", `{"country_id":"100"}`, 0, errs.KindBadRequest, `invalid value for field "country_id": must be uint8`},
+		{"null", "application/json", `null`, 0, errs.KindBadRequest, "request body must contain a single JSON object"},
+		{"string", "application/json", ` "Tere Liye"`, 0, errs.KindBadRequest, "request body must contain a single JSON object"},
+		{"array", "application/json", `[{"author":"Tere Liye"}]`, 0, errs.KindBadRequest, "request body must contain a single JSON object"},
+		{"two objects", "application/json", `{"author":"Tere Liye"}{"author":"Andrea Hirata"}`, 0, errs.KindBadRequest, "request body must contain a single JSON object"},
+		{"trailing garbage", "application/json", `{"author":"Tere Liye"} garbage`, 0, errs.KindBadRequest, "request body must contain a single JSON object"},
+		{"too large", "application/json", `{"author":"` + strings.Repeat("a", 64) + `"}`, 32, errs.KindTooLarge, "request body must not be larger than 32 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/authors", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				request.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.maxBytes > 0 {
+				request.Body = http.MaxBytesReader(httptest.NewRecorder(), request.Body, tt.maxBytes)
+			}
+
+			err := decodeJSON(request, new(authors.CreateAuthorRequest))
+			if tt.wantMessage == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if kind := errs.KindOf(err); kind != tt.wantKind {
+				t.Fatalf("invalid kind: got %s, want %s (%v)", kind, tt.wantKind, err)
+			}
+			if message := errs.MessageOf(err); message != tt.wantMessage {
+				t.Fatalf("invalid message: got %q, want %q", message, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/validation"
+	"toko-buku-api/pkg/web"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// respondWithError maps the kind of a repository or usecase error to the
+// matching http status, the one place every handler answers errors from.
+// Clients accepting application/problem+json get RFC 7807 problem details,
+// others keep the BaseResponseModel envelope
+func respondWithError(writer http.ResponseWriter, request *http.Request, err error) {
+	message := errs.MessageOf(err)
+	fields := fieldErrors(request, err)
+
+	var status int
+	var response any
+	switch errs.KindOf(err) {
+	case errs.KindNotFound:
+		status, response = http.StatusNotFound, utils.StatusNotFound()
+	case errs.KindConflict:
+		status, response = http.StatusConflict, utils.StatusConflict(message)
+	case errs.KindValidation:
+		status, response = http.StatusUnprocessableEntity, utils.StatusUnprocessableEntity(message)
+	case errs.KindFKViolation, errs.KindBadRequest:
+		status, response = http.StatusBadRequest, utils.StatusBadRequestWithMessage(message)
+	case errs.KindUnauthorized:
+		status, response = http.StatusUnauthorized, utils.StatusUnauthorized(message)
+	case errs.KindForbidden:
+		status, response = http.StatusForbidden, utils.StatusForbidden(message)
+	case errs.KindPreconditionFailed:
+		status, response = http.StatusPreconditionFailed, utils.StatusPreconditionFailed(message)
+	case errs.KindPreconditionRequired:
+		status, response = http.StatusPreconditionRequired, utils.StatusPreconditionRequired(message)
+	case errs.KindTooLarge:
+		status, response = http.StatusRequestEntityTooLarge, utils.StatusRequestEntityTooLarge(message)
+	case errs.KindUnsupportedMediaType:
+		status, response = http.StatusUnsupportedMediaType, utils.StatusUnsupportedMediaType(message)
+	default:
+		status, response = http.StatusInternalServerError, utils.StatusInternalServerError()
+	}
+
+	if web.AcceptsProblem(request) {
+		problem := utils.NewProblem(status, message, request.URL.Path)
+		problem.Errors = fields
+		utils.RespondWithProblem(writer, problem)
+		return
+	}
+
+	if len(fields) > 0 {
+		response = utils.NewResponseError(status, message, fields)
+	}
+	utils.RespondErrorWithJSON(writer, status, response)
+}
+
+// invalidID classifies an id path value that is not a number
+func invalidID(err error) error {
+	return errs.Wrap(errs.KindBadRequest, "invalid id", err)
+}
+
+// malformedBody classifies a request body that is not the expected json
+func malformedBody(err error) error {
+	return errs.Wrap(errs.KindBadRequest, "malformed request body", err)
+}
+
+// fieldErrors translates the failed validations in the chain of err into
+// one message per field, in the language the client accepts
+func fieldErrors(request *http.Request, err error) []utils.FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	trans := validation.Translator(request.Header.Get("Accept-Language"))
+	fields := make([]utils.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, utils.FieldError{
+			Field:   fieldErr.Field(),
+			Message: validation.Translate(trans, fieldErr),
+		})
+	}
+
+	return fields
+}
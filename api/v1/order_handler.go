@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"toko-buku-api/internal/orders"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/web"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Handler for order-related endpoints
+
+const (
+	headerIdempotencyKey      = "X-Idempotency-Key"
+	headerIdempotencyReplayed = "Idempotent-Replayed"
+)
+
+type OrderHandler struct {
+	Usecase orders.Usecase
+	Log     *logger.Logger
+}
+
+func NewOrderHandler(usecase orders.Usecase, logger *logger.Logger, validate *validator.Validate) *OrderHandler {
+	return &OrderHandler{
+		Usecase: usecase,
+		Log:     logger,
+	}
+}
+
+func (h OrderHandler) GetOrderById(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.GetOrderById"
+
+	orderById := request.PathValue("orderById")
+	id, err := strconv.ParseUint(orderById, 10, 64)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive get order by id: %+v with error", orderById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	claims, _ := web.GetClaims(ctx)
+	order, err := h.Usecase.GetOrderById(ctx, claims, id)
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(order)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h OrderHandler) CreateOrder(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.CreateOrder"
+
+	idempotencyKey := request.Header.Get(headerIdempotencyKey)
+	if idempotencyKey == "" {
+		h.Log.Warn(ctx, "failed to create order without idempotency key", "func_name", funcName)
+		respondWithError(writer, request, errs.BadRequest(fmt.Sprintf("%s header is required", headerIdempotencyKey)))
+		return
+	}
+
+	createRequestOrder := new(orders.CreateOrderRequest)
+	err := decodeJSON(request, createRequestOrder)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse create order with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	createRequestOrder.X_Idempotency_Key = idempotencyKey
+	if claims, ok := web.GetClaims(ctx); ok {
+		createRequestOrder.Created_By = claims.Subject
+	}
+
+	createdOrder, replayed, err := h.Usecase.CreateOrder(ctx, createRequestOrder)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to parse create order with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	if replayed {
+		writer.Header().Set(headerIdempotencyReplayed, "true")
+	}
+
+	// the stored body, replayed byte for byte
+	response := utils.StatusOK(json.RawMessage(createdOrder.Body))
+	utils.RespondWithJSON(writer, createdOrder.Status, response)
+}
+
+func (h OrderHandler) TransitionOrder(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.TransitionOrder"
+
+	orderById := request.PathValue("orderById")
+	id, err := strconv.ParseUint(orderById, 10, 64)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive transition order by id: %+v with error", orderById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	transitionRequestOrder := new(orders.TransitionOrderRequest)
+	err = decodeJSON(request, transitionRequestOrder)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse transition order with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	transitionRequestOrder.ID = id
+	// the history records who is authenticated, never who the body claims
+	if claims, ok := web.GetClaims(ctx); ok {
+		transitionRequestOrder.Changed_By = claims.Subject
+	}
+
+	order, err := h.Usecase.TransitionOrder(ctx, transitionRequestOrder)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to transition order with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(order)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h OrderHandler) GetStatusHistories(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.GetStatusHistories"
+
+	orderById := request.PathValue("orderById")
+	id, err := strconv.ParseUint(orderById, 10, 64)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive get status histories by order id: %+v with error", orderById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	claims, _ := web.GetClaims(ctx)
+	histories, err := h.Usecase.GetStatusHistories(ctx, claims, id)
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(histories)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/utils"
+)
+
+// Pagination helpers shared by the list endpoints
+
+// newPageModel builds the response pagination with self, next and prev links
+// relative to the request url
+func newPageModel(request *http.Request, page *query.Page) *utils.PageModel {
+	pageModel := &utils.PageModel{
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+		Links: map[string]string{
+			"self": request.URL.RequestURI(),
+		},
+	}
+
+	if page.HasNext {
+		values := request.URL.Query()
+		if page.Cursor {
+			values.Set("cursor", page.NextCursor)
+		} else {
+			values.Set("offset", strconv.Itoa(page.Offset+page.Limit))
+		}
+		pageModel.Links["next"] = request.URL.Path + "?" + values.Encode()
+	}
+
+	if !page.Cursor && page.Offset > 0 {
+		values := request.URL.Query()
+		values.Set("offset", strconv.Itoa(max(page.Offset-page.Limit, 0)))
+		pageModel.Links["prev"] = request.URL.Path + "?" + values.Encode()
+	}
+
+	return pageModel
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Handler for type-related endpoints
+
+type TypeHandler struct {
+	Usecase types.Usecase
+	Log     *logger.Logger
+}
+
+func NewTypeHandler(usecase types.Usecase, logger *logger.Logger, validate *validator.Validate) *TypeHandler {
+	return &TypeHandler{
+		Usecase: usecase,
+		Log:     logger,
+	}
+}
+
+func (h TypeHandler) GetTypes(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.GetTypes"
+
+	options, err := query.Parse(request.URL.Query(), &types.QuerySpec)
+	if err != nil {
+		h.Log.Warn(ctx, "receive get types with invalid query", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	bookTypes, page, err := h.Usecase.GetTypes(ctx, options)
+	if err != nil {
+		h.Log.Warn(ctx, "receive get types with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOKWithPage(bookTypes, newPageModel(request, page))
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h TypeHandler) GetTypeById(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.GetTypeById"
+
+	typeById := request.PathValue("typeById")
+	id, err := strconv.ParseUint(typeById, 10, 16)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive get type by id: %+v with error", typeById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	bookType, err := h.Usecase.GetTypeById(ctx, uint16(id))
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(bookType)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h TypeHandler) CreateType(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.CreateType"
+
+	createRequestType := new(types.CreateTypeRequest)
+	err := decodeJSON(request, createRequestType)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse create type with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	createType, err := h.Usecase.CreateType(ctx, createRequestType)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to parse create type with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(createType)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h TypeHandler) UpdateType(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.UpdateType"
+
+	typeById := request.PathValue("typeById")
+	id, err := strconv.ParseUint(typeById, 10, 16)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive update type by id: %+v with error", typeById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	updateRequestType := new(types.UpdateTypeRequest)
+	err = decodeJSON(request, updateRequestType)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse update type with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+	updateRequestType.ID = uint16(id)
+
+	typeResponse, err := h.Usecase.UpdateType(ctx, updateRequestType)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to parse update type with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(typeResponse)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h TypeHandler) DeleteType(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.DeleteType"
+
+	typeById := request.PathValue("typeById")
+	id, err := strconv.ParseUint(typeById, 10, 16)
+	if err != nil {
+		h.Log.Warn(ctx, fmt.Sprintf("receive delete type by id: %+v with error", typeById), "error", err, "func_name", funcName)
+		respondWithError(writer, request, invalidID(err))
+		return
+	}
+
+	err = h.Usecase.DeleteType(ctx, uint16(id))
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(struct{}{})
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"net/http"
+	"toko-buku-api/internal/users"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Handler for user account endpoints
+
+type UserHandler struct {
+	Usecase users.Usecase
+	Log     *logger.Logger
+}
+
+func NewUserHandler(usecase users.Usecase, logger *logger.Logger, validate *validator.Validate) *UserHandler {
+	return &UserHandler{
+		Usecase: usecase,
+		Log:     logger,
+	}
+}
+
+func (h UserHandler) RegisterUser(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.RegisterUser"
+
+	registerRequestUser := new(users.RegisterUserRequest)
+	err := decodeJSON(request, registerRequestUser)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse register user with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	user, err := h.Usecase.RegisterUser(ctx, registerRequestUser)
+	if err != nil {
+		h.Log.Warn(ctx, "invalid to register user with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK(user)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h UserHandler) ForgotPassword(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.ForgotPassword"
+
+	forgotRequestPassword := new(users.ForgotPasswordRequest)
+	err := decodeJSON(request, forgotRequestPassword)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse forgot password with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	err = h.Usecase.ForgotPassword(ctx, forgotRequestPassword)
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK[any](nil)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
+
+func (h UserHandler) ResetPassword(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	funcName := "handler.ResetPassword"
+
+	resetRequestPassword := new(users.ResetPasswordRequest)
+	err := decodeJSON(request, resetRequestPassword)
+	if err != nil {
+		h.Log.Warn(ctx, "failed to parse reset password with error request", "error", err, "func_name", funcName)
+		respondWithError(writer, request, err)
+		return
+	}
+
+	err = h.Usecase.ResetPassword(ctx, resetRequestPassword)
+	if err != nil {
+		respondWithError(writer, request, err)
+		return
+	}
+
+	response := utils.StatusOK[any](nil)
+	utils.RespondWithJSON(writer, http.StatusOK, response)
+}
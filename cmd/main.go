@@ -11,8 +11,9 @@ import (
 	"time"
 	"toko-buku-api/config"
 	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store/memory"
+	"toko-buku-api/pkg/validation"
 
-	"github.com/go-playground/validator/v10"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -22,13 +23,38 @@ import (
 
 func main() {
 	viper := config.NewViper()
-	log := logger.New(os.Stdout, logger.LevelDebug, "MAIN", nil)
-	db := config.NewDatabase(viper, log)
-	validate := validator.New()
+	log := config.NewLogger("MAIN")
+	validate, err := validation.Default()
+	if err != nil {
+		log.Fatal(context.Background(), "startup", "error", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		db := config.NewDatabase(viper, log)
+		err := runMigrate(context.Background(), db, log, os.Args[2:])
+		db.Close()
+		if err != nil {
+			log.Error(context.Background(), "migrate", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var db *sql.DB
+	var memoryDB *memory.DB
+	if viper.GetString("database.driver") == config.DriverMemory {
+		memoryDB = config.NewMemoryDatabase(log)
+	} else {
+		db = config.NewDatabase(viper, log)
+		if err := config.VerifySchema(context.Background(), db); err != nil {
+			log.Fatal(context.Background(), "startup", "error", err)
+		}
+	}
 
 	routing := config.NewApp(&config.AppConfig{
 		Viper:    viper,
 		DB:       db,
+		Memory:   memoryDB,
 		Log:      log,
 		Validate: validate,
 	})
@@ -84,6 +110,9 @@ func main() {
 
 		log.Info(ctx, "shutdown", "status", "database close started", "status", "waiting...")
 		defer func() {
+			if db == nil {
+				return
+			}
 			err := db.Close()
 			if err != nil {
 				log.Fatal(context.Background(), "got error when closing the DB connection", err)
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"toko-buku-api/config"
+	"toko-buku-api/db/migrations"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/migrate"
+)
+
+// Entrypoint for the migrate subcommand
+
+const migrateUsage = `usage: toko-buku-api migrate <command>
+
+commands:
+  up [N]      apply all or the next N pending migrations
+  down [N]    roll the last or the last N applied migrations back
+  status      list the migrations and whether they are applied
+  force V     mark the migrations up to version V as applied, without running them
+  to V        migrate up or down to version V, 0 rolls every migration back
+  verify      compare the database schema with the tables the repositories query`
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+func runMigrate(ctx context.Context, db *sql.DB, log *logger.Logger, args []string) error {
+	loaded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return err
+	}
+	migrator := migrate.New(db, loaded, log)
+
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	switch command, args := args[0], args[1:]; command {
+	case "up":
+		n, err := optionalArg(args)
+		if err != nil {
+			return err
+		}
+		return migrator.Up(ctx, n)
+
+	case "down":
+		n, err := optionalArg(args)
+		if err != nil {
+			return err
+		}
+		return migrator.Down(ctx, max(n, 1))
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+		return nil
+
+	case "verify":
+		if err := config.VerifySchema(ctx, db); err != nil {
+			return err
+		}
+		log.Info(ctx, "migrate", "status", "schema verified")
+		return nil
+
+	case "force", "to":
+		if len(args) != 1 {
+			return errMigrateUsage
+		}
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[0], err)
+		}
+		if command == "force" {
+			return migrator.Force(ctx, version)
+		}
+		return migrator.To(ctx, version)
+	}
+
+	return errMigrateUsage
+}
+
+// optionalArg parses the optional count of the up and down commands, 0 when
+// missing.
+func optionalArg(args []string) (int, error) {
+	switch len(args) {
+	case 0:
+		return 0, nil
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid count %q", args[0])
+		}
+		return n, nil
+	}
+
+	return 0, errMigrateUsage
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		switch {
+		case status.Dirty:
+			state = "dirty"
+		case status.Applied && status.Up == "":
+			state = "missing file"
+		case status.Modified:
+			state = "modified"
+		case status.Applied:
+			state = "applied"
+		}
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+
+	w.Flush()
+}
@@ -1,12 +1,25 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	v1 "toko-buku-api/api/v1"
+	"toko-buku-api/internal/apikeys"
 	"toko-buku-api/internal/authors"
+	"toko-buku-api/internal/books"
 	"toko-buku-api/internal/countries"
+	"toko-buku-api/internal/orders"
+	"toko-buku-api/internal/roles"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/internal/users"
+	"toko-buku-api/pkg/auth"
 	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/ratelimit"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+	"toko-buku-api/pkg/web"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
@@ -14,47 +27,223 @@ import (
 
 // Configurations files and setup
 
+// AppConfig wires the repositories to MySQL through DB, or to the in-memory
+// database when Memory is set. Events run for the lines logged by the
+// middlewares, like recovered panics. RateLimitStore keeps the rate limits
+// of the clients, in the memory of the process when nil
 type AppConfig struct {
-	Viper    *viper.Viper
-	DB       *sql.DB
-	Log      *logger.Logger
-	Validate *validator.Validate
+	Viper          *viper.Viper
+	DB             *sql.DB
+	Memory         *memory.DB
+	Log            *logger.Logger
+	Validate       *validator.Validate
+	Events         logger.Events
+	RateLimitStore ratelimit.Store
 }
 
-func NewApp(appConfig *AppConfig) *http.ServeMux {
-	// typeRepository := types.NewRepository(appDB, appLog)
-	// typeService := service.NewTypeService(typeRepository, db, validate)
-	// typeController := controller.NewTypeController(typeService)
+// NewApp routes every endpoint and wraps the router with the middlewares
+// shared by every route
+func NewApp(appConfig *AppConfig) http.Handler {
+	mux := http.NewServeMux()
 
-	// bookRepository := repository.NewTypeRepository()
-	// bookService := service.NewTypeService(bookRepository, db, validate)
-	// bookController := controller.NewTypeController(bookService)
+	config := appConfig.Viper
+	if config == nil {
+		config = viper.New()
+		setDefaults(config)
+	}
 
-	mux := http.NewServeMux()
+	var tx store.Beginner = store.NewSQLBeginner(appConfig.DB)
+	if appConfig.Memory != nil {
+		tx = appConfig.Memory
+	}
+
+	// sign the list cursors with the key shared by the instances, a random
+	// one of the process when unset
+	if secret := config.GetString("query.cursorSecret"); secret != "" {
+		query.SetCursorKey([]byte(secret))
+	} else {
+		NewLogger("QUERY").Warn(context.Background(), "query.cursorSecret is not set: list cursors are valid on this instance until it restarts")
+	}
+
+	// handle user account endpoints
+	userLog := NewLogger("USER")
+
+	var userRepository users.Repository = users.NewMySQLRepository(appConfig.DB, userLog)
+	if appConfig.Memory != nil {
+		userRepository = users.NewMemoryRepository(userLog)
+	}
+	var mailer users.Mailer = users.LogMailer{Log: userLog}
+	if config.GetBool("mail.devLogTokens") {
+		userLog.Warn(context.Background(), "mail.devLogTokens is set: password reset tokens are logged, never set it in production")
+		mailer = users.DevMailer{Log: userLog}
+	}
+	userUsecase := users.NewUsecase(userRepository, tx, userLog, appConfig.Validate, mailer)
+	userHandler := v1.NewUserHandler(userUsecase, userLog, appConfig.Validate)
+	mux.HandleFunc("POST /users", userHandler.RegisterUser)
+	mux.HandleFunc("POST /auth/password/forgot", userHandler.ForgotPassword)
+	mux.HandleFunc("POST /auth/password/reset", userHandler.ResetPassword)
+
+	// handle auth-related endpoints, logging the configured users in before
+	// the registered ones
+	authLog := NewLogger("AUTH")
+
+	tokens, err := NewJWT(config)
+	if err != nil {
+		authLog.Fatal(context.Background(), "failed to build the token signer", "error", err)
+	}
+	staticUsers, err := NewStaticUsers(config)
+	if err != nil {
+		authLog.Fatal(context.Background(), "failed to read the users", "error", err)
+	}
+	credentials := auth.Chain{staticUsers, &userUsecase}
+	authHandler := v1.NewAuthHandler(credentials, tokens, authLog, appConfig.Validate)
+	mux.HandleFunc("POST /auth/token", authHandler.CreateToken)
+
+	// authorize the routes with the permissions of the roles
+	roleLog := NewLogger("ROLE")
+
+	var roleRepository roles.Repository = roles.NewMySQLRepository(appConfig.DB, roleLog)
+	if appConfig.Memory != nil {
+		roleRepository = roles.NewMemoryRepository(roleLog)
+	}
+	roleUsecase := roles.NewUsecase(roleRepository, tx, roleLog)
+	policy, err := roleUsecase.GetPolicy(context.Background())
+	if err != nil {
+		roleLog.Fatal(context.Background(), "failed to load the role permissions", "error", err)
+	}
+	authorize := func(permission auth.Permission, handler http.HandlerFunc) http.Handler {
+		return web.Authorize(policy, permission)(handler)
+	}
+
+	// handle API key endpoints
+	apiKeyLog := NewLogger("APIKEY")
+
+	var apiKeyRepository apikeys.Repository = apikeys.NewMySQLRepository(appConfig.DB, apiKeyLog)
+	if appConfig.Memory != nil {
+		apiKeyRepository = apikeys.NewMemoryRepository(apiKeyLog)
+	}
+	apiKeyUsecase := apikeys.NewUsecase(apiKeyRepository, tx, apiKeyLog, appConfig.Validate, policy)
+	apiKeyHandler := v1.NewAPIKeyHandler(apiKeyUsecase, apiKeyLog, appConfig.Validate)
+	mux.Handle("GET /api-keys", authorize(auth.APIKeysRead, apiKeyHandler.GetAPIKeys))
+	mux.Handle("POST /api-keys", authorize(auth.APIKeysWrite, apiKeyHandler.CreateAPIKey))
+	mux.Handle("DELETE /api-keys/{apiKeyById}", authorize(auth.APIKeysWrite, apiKeyHandler.RevokeAPIKey))
 
 	// handle author-related endpoints
-	authorLog := logger.NewService("AUTHOR")
+	authorLog := NewLogger("AUTHOR")
 
-	authorRepository := authors.NewRepository(appConfig.DB, authorLog)
-	authorUsecase := authors.NewUsecase(authorRepository, authorLog, appConfig.Validate)
+	var authorRepository authors.Repository = authors.NewMySQLRepository(appConfig.DB, authorLog)
+	if appConfig.Memory != nil {
+		authorRepository = authors.NewMemoryRepository(authorLog)
+	}
+	authorUsecase := authors.NewUsecase(authorRepository, tx, authorLog, appConfig.Validate)
 	authorHandler := v1.NewAuthorHandler(authorUsecase, authorLog, appConfig.Validate)
+	authorHandler.RequireIfMatch = config.GetBool("conditional.requireIfMatch")
 	mux.HandleFunc("GET /authors", authorHandler.GetAuthors)
 	mux.HandleFunc("GET /authors/{authorById}", authorHandler.GetAuthorById)
-	mux.HandleFunc("POST /authors", authorHandler.CreateAuthor)
-	mux.HandleFunc("PUT /authors/{authorById}", authorHandler.UpdateAuthor)
-	mux.HandleFunc("DELETE /authors/{authorById}", authorHandler.DeleteAuthor)
+	mux.Handle("POST /authors", authorize(auth.AuthorsWrite, authorHandler.CreateAuthor))
+	mux.Handle("PUT /authors/{authorById}", authorize(auth.AuthorsWrite, authorHandler.UpdateAuthor))
+	mux.Handle("DELETE /authors/{authorById}", authorize(auth.AuthorsDelete, authorHandler.DeleteAuthor))
 
 	// handle country-related endpoints
-	countryLog := logger.NewService("COUNTRY")
+	countryLog := NewLogger("COUNTRY")
 
-	countryRepository := countries.NewRepository(appConfig.DB, countryLog)
-	countryUsecase := countries.NewUsecase(countryRepository, countryLog, appConfig.Validate)
+	var countryRepository countries.Repository = countries.NewMySQLRepository(appConfig.DB, countryLog)
+	if appConfig.Memory != nil {
+		countryRepository = countries.NewMemoryRepository(countryLog)
+	}
+	countryUsecase := countries.NewUsecase(countryRepository, tx, countryLog, appConfig.Validate)
 	countryHandler := v1.NewCountryHandler(countryUsecase, countryLog, appConfig.Validate)
+	countryHandler.RequireIfMatch = config.GetBool("conditional.requireIfMatch")
 	mux.HandleFunc("GET /countries", countryHandler.GetCountries)
 	mux.HandleFunc("GET /countries/{countryById}", countryHandler.GetCountryById)
-	mux.HandleFunc("POST /countries", countryHandler.CreateCountry)
-	mux.HandleFunc("PUT /countries/{countryById}", countryHandler.UpdateCountry)
-	mux.HandleFunc("DELETE /countries/{countryById}", countryHandler.DeleteAuthor)
+	mux.Handle("POST /countries", authorize(auth.CountriesWrite, countryHandler.CreateCountry))
+	mux.Handle("PUT /countries/{countryById}", authorize(auth.CountriesWrite, countryHandler.UpdateCountry))
+	mux.Handle("DELETE /countries/{countryById}", authorize(auth.CountriesDelete, countryHandler.DeleteAuthor))
+
+	// handle type-related endpoints
+	typeLog := NewLogger("TYPE")
+
+	var typeRepository types.Repository = types.NewMySQLRepository(appConfig.DB, typeLog)
+	if appConfig.Memory != nil {
+		typeRepository = types.NewMemoryRepository(typeLog)
+	}
+	typeUsecase := types.NewUsecase(typeRepository, tx, typeLog, appConfig.Validate)
+	typeHandler := v1.NewTypeHandler(typeUsecase, typeLog, appConfig.Validate)
+	mux.HandleFunc("GET /types", typeHandler.GetTypes)
+	mux.HandleFunc("GET /types/{typeById}", typeHandler.GetTypeById)
+	mux.Handle("POST /types", authorize(auth.TypesWrite, typeHandler.CreateType))
+	mux.Handle("PUT /types/{typeById}", authorize(auth.TypesWrite, typeHandler.UpdateType))
+	mux.Handle("DELETE /types/{typeById}", authorize(auth.TypesDelete, typeHandler.DeleteType))
+
+	// handle book-related endpoints
+	bookLog := NewLogger("BOOK")
+
+	if err := books.RegisterValidations(appConfig.Validate); err != nil {
+		bookLog.Fatal(context.Background(), "failed to register book validations", "error", err)
+	}
+
+	var bookRepository books.Repository = books.NewMySQLRepository(appConfig.DB, bookLog)
+	if appConfig.Memory != nil {
+		bookRepository = books.NewMemoryRepository(bookLog)
+	}
+	bookUsecase := books.NewUsecase(bookRepository, tx, bookLog, appConfig.Validate)
+	bookHandler := v1.NewBookHandler(bookUsecase, bookLog, appConfig.Validate)
+	mux.HandleFunc("GET /books", bookHandler.GetBooks)
+	mux.HandleFunc("GET /books/{bookById}", bookHandler.GetBookById)
+	mux.Handle("POST /books", authorize(auth.BooksWrite, bookHandler.CreateBook))
+	mux.Handle("PUT /books/{bookById}", authorize(auth.BooksWrite, bookHandler.UpdateBook))
+	mux.Handle("DELETE /books/{bookById}", authorize(auth.BooksDelete, bookHandler.DeleteBook))
+
+	// handle order-related endpoints
+	orderLog := NewLogger("ORDER")
+
+	var orderRepository orders.Repository = orders.NewMySQLRepository(appConfig.DB, orderLog)
+	if appConfig.Memory != nil {
+		orderRepository = orders.NewMemoryRepository(orderLog)
+	}
+	orderUsecase := orders.NewUsecase(orderRepository, tx, orderLog, appConfig.Validate, policy)
+	orderHandler := v1.NewOrderHandler(orderUsecase, orderLog, appConfig.Validate)
+	// customers read the orders they placed, the usecase tells which
+	readOrders := web.AuthorizeAny(policy, auth.OrdersRead, auth.OrdersReadOwn)
+	mux.Handle("GET /orders/{orderById}", readOrders(http.HandlerFunc(orderHandler.GetOrderById)))
+	mux.Handle("POST /orders", authorize(auth.OrdersWrite, orderHandler.CreateOrder))
+	mux.Handle("GET /orders/{orderById}/transitions", readOrders(http.HandlerFunc(orderHandler.GetStatusHistories)))
+	mux.Handle("POST /orders/{orderById}/transitions", authorize(auth.OrdersTransition, orderHandler.TransitionOrder))
+
+	accessLog := web.AccessLog(NewLoggerWithEvents("ACCESS", appConfig.Events), config.GetFloat64("log.access.sampleRate"))
+	recoverPanic := web.Recover(NewLoggerWithEvents("PANIC", appConfig.Events))
+
+	compressOptions, err := NewCompressOptions(config)
+	if err != nil {
+		NewLogger("COMPRESS").Fatal(context.Background(), "failed to read the compression options", "error", err)
+	}
+	compress := web.Compress(compressOptions)
+
+	limitBody := web.LimitBody(config.GetInt64("request.maxBodySize"))
+
+	authenticate := web.Authenticate(web.Bearer(tokens), web.APIKey(&apiKeyUsecase))
+
+	rateLimitLog := NewLoggerWithEvents("RATELIMIT", appConfig.Events)
+	rateLimits, err := NewRateLimits(config)
+	if err != nil {
+		rateLimitLog.Fatal(context.Background(), "failed to read the rate limits", "error", err)
+	}
+	rateLimitStore := appConfig.RateLimitStore
+	if rateLimitStore == nil {
+		rateLimitStore = ratelimit.NewMemoryStore()
+	}
+	rateLimit := web.RateLimit(rateLimitLog, rateLimitStore, rateLimits)
+	failedAuthLimit, err := NewFailedAuthLimit(config)
+	if err != nil {
+		rateLimitLog.Fatal(context.Background(), "failed to read the failed authentication limit", "error", err)
+	}
+	// count the failed authentications before authenticating, to stop the
+	// guessing of credentials before the API keys are looked up
+	limitFailedAuth := web.LimitFailedAuth(rateLimitLog, rateLimitStore, failedAuthLimit)
+
+	// answer the preflights before authentication, and add the CORS headers
+	// to its rejections
+	cors := web.CORS(NewCORSOptions(config), mux)
 
-	return mux
+	return web.Wrap(mux, web.Trace, accessLog, recoverPanic, compress, cors, limitBody, limitFailedAuth, authenticate, rateLimit)
 }
@@ -0,0 +1,746 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"toko-buku-api/pkg/validation"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	testSecret = "toko-buku-api-test-secret-0123456789"
+
+	// testPasswordHash is the bcrypt hash of "rahasia!"
+	testPasswordHash = "$2a$10$VNTskvH70mjno16wjgte0uc6nI1NrSgERgNWYc4ZVentiXJNZJZgG"
+)
+
+// newMemoryServer serves the app on the in-memory database, with the config
+// changed by configure
+func newMemoryServer(t *testing.T, configure ...func(config *viper.Viper)) *httptest.Server {
+	log := NewLogger("TEST")
+	validate, err := validation.Default()
+	if err != nil {
+		t.Fatalf("failed to build validator: %v", err)
+	}
+
+	config := viper.New()
+	setDefaults(config)
+	config.Set("auth.secret", testSecret)
+	config.Set("auth.users", []map[string]any{
+		{"username": "admin", "passwordHash": testPasswordHash, "role": "admin"},
+		{"username": "staff", "passwordHash": testPasswordHash, "role": "staff"},
+		{"username": "customer", "passwordHash": testPasswordHash, "role": "customer"},
+	})
+	for _, fn := range configure {
+		fn(config)
+	}
+
+	mux := NewApp(&AppConfig{
+		Viper:    config,
+		Memory:   NewMemoryDatabase(log),
+		Log:      log,
+		Validate: validate,
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doJSON(t *testing.T, method, url, body string, header http.Header, data any) *http.Response {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer response.Body.Close()
+
+	if data != nil {
+		if err := json.NewDecoder(response.Body).Decode(data); err != nil {
+			t.Fatalf("failed to decode response body: %v", err)
+		}
+	}
+
+	return response
+}
+
+// login returns the Authorization header of the user
+func login(t *testing.T, server *httptest.Server, username string) http.Header {
+	var token struct {
+		Data struct {
+			AccessToken string `json:"access_token"`
+		}
+	}
+	response := doJSON(t, http.MethodPost, server.URL+"/auth/token", `{"username":"`+username+`","password":"rahasia!"}`, nil, &token)
+	if response.StatusCode != http.StatusOK || token.Data.AccessToken == "" {
+		t.Fatalf("failed to log in: got %d", response.StatusCode)
+	}
+
+	return http.Header{"Authorization": {"Bearer " + token.Data.AccessToken}}
+}
+
+func TestNewApp_memory(t *testing.T) {
+	server := newMemoryServer(t)
+
+	var bookList struct {
+		Data []struct {
+			ID     uint32
+			Sku    string
+			Stock  uint32
+			Author struct{ Author string }
+		}
+		Total int
+	}
+	response := doJSON(t, http.MethodGet, server.URL+"/books?author~=hamka", "", nil, &bookList)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid get books status code: got %d, want 200", response.StatusCode)
+	}
+	if len(bookList.Data) != 1 || bookList.Data[0].Author.Author != "Buya Hamka" || bookList.Total != 1 {
+		t.Fatalf("invalid books: got %+v", bookList)
+	}
+	if response.Header.Get("X-Request-ID") == "" {
+		t.Fatalf("invalid response headers: want an X-Request-ID")
+	}
+
+	header := login(t, server, "customer")
+	header.Set("X-Idempotency-Key", "4f1c2a9e-7d1b-4b8e-9a57-0b1e3c5d7f90")
+	orderBody := `{"books":[{"book_id":1,"quantity":2}]}`
+	response = doJSON(t, http.MethodPost, server.URL+"/orders", orderBody, header, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid create order status code: got %d, want 200", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodPost, server.URL+"/orders", orderBody, header, nil)
+	if response.StatusCode != http.StatusOK || response.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("invalid replayed order: got %d %v", response.StatusCode, response.Header)
+	}
+
+	var book struct {
+		Data struct{ Stock uint32 }
+	}
+	doJSON(t, http.MethodGet, server.URL+"/books/1", "", nil, &book)
+	if book.Data.Stock != 98 {
+		t.Fatalf("invalid stock after order: got %d, want 98", book.Data.Stock)
+	}
+
+	response = doJSON(t, http.MethodDelete, server.URL+"/types/1", "", login(t, server, "staff"), nil)
+	if response.StatusCode != http.StatusConflict {
+		t.Fatalf("invalid delete type in use status code: got %d, want 409", response.StatusCode)
+	}
+}
+
+func TestNewApp_errorStatus(t *testing.T) {
+	server := newMemoryServer(t)
+	header := login(t, server, "admin")
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		// wantMessage, when set, is the message of the body
+		wantMessage string
+	}{
+		{"missing author", http.MethodGet, "/authors/999", "", http.StatusNotFound, ""},
+		{"non numeric author id", http.MethodGet, "/authors/abc", "", http.StatusBadRequest, ""},
+		{"non numeric country id", http.MethodDelete, "/countries/abc", "", http.StatusBadRequest, "invalid id"},
+		{"malformed country", http.MethodPost, "/countries", `{"iso3":`, http.StatusBadRequest, "malformed request body"},
+		{"invalid country sort", http.MethodGet, "/countries?sort=unknown", "", http.StatusBadRequest, `invalid query: cannot sort by "unknown"`},
+		{"invalid book", http.MethodPost, "/books", `{"title":""}`, http.StatusUnprocessableEntity, ""},
+		{"book of missing author", http.MethodPost, "/books", `{"author_id":999,"title":"Tenggelamnya","sku":"tenggelamnya-999","price":1000,"stock":1}`, http.StatusBadRequest, ""},
+		{"invalid sort", http.MethodGet, "/books?sort=unknown", "", http.StatusBadRequest, ""},
+		{"country in use", http.MethodDelete, "/countries/100", "", http.StatusConflict, "resource is still referenced"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body struct {
+				Status  int
+				Message string
+			}
+			response := doJSON(t, tc.method, server.URL+tc.path, tc.body, header, &body)
+			if response.StatusCode != tc.wantStatus || body.Status != tc.wantStatus {
+				t.Fatalf("invalid status: got %d (%d %q), want %d", response.StatusCode, body.Status, body.Message, tc.wantStatus)
+			}
+			if tc.wantMessage != "" && body.Message != tc.wantMessage {
+				t.Fatalf("invalid message: got %q, want %q", body.Message, tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestNewApp_problem(t *testing.T) {
+	server := newMemoryServer(t)
+	body := `{"country_id":100,"author":"Ab"}`
+	header := login(t, server, "staff")
+
+	var problem struct {
+		Type     string
+		Status   int
+		Instance string
+		Errors   []struct{ Field, Message string }
+	}
+	header.Set("Accept", "application/problem+json")
+	response := doJSON(t, http.MethodPost, server.URL+"/authors", body, header, &problem)
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/problem+json" {
+		t.Fatalf("invalid content type: got %s, want application/problem+json", contentType)
+	}
+	if problem.Status != http.StatusUnprocessableEntity || problem.Type != "about:blank" || problem.Instance != "/authors" {
+		t.Fatalf("invalid problem: got %+v", problem)
+	}
+	if len(problem.Errors) != 2 || problem.Errors[0].Field != "author" || problem.Errors[1].Message != "city is a required field" {
+		t.Fatalf("invalid problem errors: got %+v", problem.Errors)
+	}
+
+	var envelope struct {
+		Status int
+		Error  []struct{ Field, Message string }
+	}
+	header.Del("Accept")
+	response = doJSON(t, http.MethodPost, server.URL+"/authors", body, header, &envelope)
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Fatalf("invalid content type: got %s, want application/json", contentType)
+	}
+	if envelope.Status != http.StatusUnprocessableEntity || len(envelope.Error) != 2 {
+		t.Fatalf("invalid envelope: got %+v", envelope)
+	}
+}
+
+func TestNewApp_authorize(t *testing.T) {
+	server := newMemoryServer(t)
+	body := `{"country_id":100,"author":"Pramoedya Ananta Toer","city":"Blora"}`
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"anonymous author write", http.MethodPost, "/authors", nil, http.StatusUnauthorized},
+		{"anonymous country delete", http.MethodDelete, "/countries/226", nil, http.StatusUnauthorized},
+		{"anonymous book write", http.MethodPost, "/books", nil, http.StatusUnauthorized},
+		{"invalid token", http.MethodPost, "/authors", http.Header{"Authorization": {"Bearer abc.def.ghi"}}, http.StatusUnauthorized},
+		{"invalid scheme", http.MethodPost, "/authors", http.Header{"Authorization": {"Basic c3RhZmY="}}, http.StatusUnauthorized},
+		{"anonymous read", http.MethodGet, "/authors", nil, http.StatusOK},
+		{"staff author write", http.MethodPost, "/authors", login(t, server, "staff"), http.StatusOK},
+		{"customer author write", http.MethodPost, "/authors", login(t, server, "customer"), http.StatusForbidden},
+		{"staff country delete", http.MethodDelete, "/countries/226", login(t, server, "staff"), http.StatusForbidden},
+		{"admin country delete", http.MethodDelete, "/countries/226", login(t, server, "admin"), http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var response struct {
+				Status  int
+				Message string
+			}
+			res := doJSON(t, tc.method, server.URL+tc.path, body, tc.header, &response)
+			if res.StatusCode != tc.wantStatus || response.Status != tc.wantStatus {
+				t.Fatalf("invalid status: got %d (%d %q), want %d", res.StatusCode, response.Status, response.Message, tc.wantStatus)
+			}
+		})
+	}
+
+	response := doJSON(t, http.MethodPost, server.URL+"/auth/token", `{"username":"staff","password":"salah"}`, nil, nil)
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("invalid login with a wrong password status code: got %d, want 401", response.StatusCode)
+	}
+}
+
+func TestNewApp_users(t *testing.T) {
+	server := newMemoryServer(t)
+	body := `{"email":"budi@example.com","password":"rahasia!"}`
+
+	var user struct {
+		Data struct {
+			ID    uint32
+			Email string
+			Role  string
+		}
+	}
+	response := doJSON(t, http.MethodPost, server.URL+"/users", body, nil, &user)
+	if response.StatusCode != http.StatusOK || user.Data.Email != "budi@example.com" || user.Data.Role != "customer" {
+		t.Fatalf("invalid registered user: got %d %+v", response.StatusCode, user.Data)
+	}
+
+	response = doJSON(t, http.MethodPost, server.URL+"/users", body, nil, nil)
+	if response.StatusCode != http.StatusConflict {
+		t.Fatalf("invalid register of a registered email status code: got %d, want 409", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodPost, server.URL+"/auth/token", `{"username":"budi@example.com","password":"rahasia!"}`, nil, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid login of a registered user status code: got %d, want 200", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodPost, server.URL+"/auth/password/forgot", `{"email":"siti@example.com"}`, nil, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid forgot password of an unknown email status code: got %d, want 200", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodPost, server.URL+"/auth/password/reset", `{"token":"unknown","password":"rahasia-baru"}`, nil, nil)
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid reset password with an unknown token status code: got %d, want 400", response.StatusCode)
+	}
+}
+
+func TestNewApp_apiKeys(t *testing.T) {
+	server := newMemoryServer(t)
+	admin := login(t, server, "admin")
+
+	response := doJSON(t, http.MethodPost, server.URL+"/api-keys", `{"name":"warehouse","scopes":["books:write"]}`, login(t, server, "staff"), nil)
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("invalid staff create api key status code: got %d, want 403", response.StatusCode)
+	}
+
+	var created struct {
+		Data struct {
+			ID  uint32
+			Key string
+		}
+	}
+	response = doJSON(t, http.MethodPost, server.URL+"/api-keys", `{"name":"warehouse","scopes":["authors:write"]}`, admin, &created)
+	if response.StatusCode != http.StatusOK || created.Data.Key == "" {
+		t.Fatalf("invalid created api key: got %d %+v", response.StatusCode, created.Data)
+	}
+
+	header := http.Header{"X-Api-Key": {created.Data.Key}}
+	body := `{"country_id":100,"author":"Pramoedya Ananta Toer","city":"Blora"}`
+	response = doJSON(t, http.MethodPost, server.URL+"/authors", body, header, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid api key author write status code: got %d, want 200", response.StatusCode)
+	}
+	response = doJSON(t, http.MethodDelete, server.URL+"/countries/226", "", header, nil)
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("invalid api key country delete status code: got %d, want 403", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodDelete, server.URL+"/api-keys/"+strconv.FormatUint(uint64(created.Data.ID), 10), "", admin, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid revoke api key status code: got %d, want 200", response.StatusCode)
+	}
+	response = doJSON(t, http.MethodPost, server.URL+"/authors", body, header, nil)
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("invalid revoked api key status code: got %d, want 401", response.StatusCode)
+	}
+}
+
+func TestNewApp_rateLimit(t *testing.T) {
+	server := newMemoryServer(t, func(config *viper.Viper) {
+		config.Set("rateLimit.groups", map[string]any{
+			"authors": map[string]any{"requestsPerMinute": 6, "burst": 2},
+		})
+	})
+
+	for i := 0; i < 2; i++ {
+		response := doJSON(t, http.MethodGet, server.URL+"/authors", "", nil, nil)
+		if response.StatusCode != http.StatusOK || response.Header.Get("RateLimit-Remaining") != strconv.Itoa(1-i) {
+			t.Fatalf("invalid response %d: got %d %v", i, response.StatusCode, response.Header)
+		}
+	}
+
+	var body struct {
+		Status  int
+		Message string
+	}
+	response := doJSON(t, http.MethodGet, server.URL+"/authors", "", nil, &body)
+	if response.StatusCode != http.StatusTooManyRequests || body.Status != http.StatusTooManyRequests {
+		t.Fatalf("invalid status over the limit: got %d (%d %q), want 429", response.StatusCode, body.Status, body.Message)
+	}
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "10" {
+		t.Fatalf("invalid Retry-After: got %q, want 10", retryAfter)
+	}
+
+	response = doJSON(t, http.MethodGet, server.URL+"/books", "", nil, nil)
+	if response.StatusCode != http.StatusOK || response.Header.Get("RateLimit-Limit") != "" {
+		t.Fatalf("invalid response of a group without limit: got %d %v", response.StatusCode, response.Header)
+	}
+}
+
+func TestNewApp_rateLimitFailedAuth(t *testing.T) {
+	server := newMemoryServer(t)
+	header := http.Header{"X-API-Key": {"tbk_00000000_guess"}}
+
+	// the default limit lets 10 failed authentications through
+	for i := 0; i < 10; i++ {
+		response := doJSON(t, http.MethodGet, server.URL+"/authors", "", header, nil)
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("invalid status code of guess %d: got %d, want 401", i, response.StatusCode)
+		}
+	}
+	response := doJSON(t, http.MethodGet, server.URL+"/authors", "", header, nil)
+	if response.StatusCode != http.StatusTooManyRequests || response.Header.Get("Retry-After") == "" {
+		t.Fatalf("invalid status code over the limit: got %d %v, want 429", response.StatusCode, response.Header)
+	}
+
+	response = doJSON(t, http.MethodGet, server.URL+"/authors", "", http.Header{"Authorization": {"Bearer invalid"}}, nil)
+	if response.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("invalid status code of a bearer token over the limit: got %d, want 429", response.StatusCode)
+	}
+	response = doJSON(t, http.MethodGet, server.URL+"/authors", "", nil, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid status code of an anonymous request: got %d, want 200", response.StatusCode)
+	}
+}
+
+func TestNewApp_cors(t *testing.T) {
+	server := newMemoryServer(t, func(config *viper.Viper) {
+		config.Set("cors.allowedOrigins", []string{"https://toko.example.com"})
+		config.Set("cors.allowCredentials", true)
+	})
+
+	header := http.Header{
+		"Origin":                         {"https://toko.example.com"},
+		"Access-Control-Request-Method":  {"DELETE"},
+		"Access-Control-Request-Headers": {"authorization"},
+	}
+	response := doJSON(t, http.MethodOptions, server.URL+"/countries/100", "", header, nil)
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf("invalid preflight status code: got %d, want 204", response.StatusCode)
+	}
+	if methods := response.Header.Get("Access-Control-Allow-Methods"); methods != "GET, PUT, DELETE" {
+		t.Fatalf("invalid allowed methods: got %q", methods)
+	}
+	if credentials := response.Header.Get("Access-Control-Allow-Credentials"); credentials != "true" {
+		t.Fatalf("invalid allowed credentials: got %q", credentials)
+	}
+
+	// rejections of the authentication carry the CORS headers too
+	header = http.Header{"Origin": {"https://toko.example.com"}}
+	response = doJSON(t, http.MethodDelete, server.URL+"/countries/100", "", header, nil)
+	if response.StatusCode != http.StatusUnauthorized || response.Header.Get("Access-Control-Allow-Origin") != "https://toko.example.com" {
+		t.Fatalf("invalid anonymous delete: got %d %v", response.StatusCode, response.Header)
+	}
+}
+
+func TestNewApp_compression(t *testing.T) {
+	server := newMemoryServer(t, func(config *viper.Viper) {
+		config.Set("compression.minSize", 1)
+	})
+
+	header := http.Header{"Accept-Encoding": {"gzip"}}
+	response := doJSON(t, http.MethodGet, server.URL+"/countries", "", header, nil)
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("invalid compressed response: got %d %v", response.StatusCode, response.Header)
+	}
+
+	// the client asks for gzip and decodes it by itself
+	var countries struct {
+		Data []map[string]any `json:"data"`
+	}
+	response = doJSON(t, http.MethodGet, server.URL+"/countries", "", nil, &countries)
+	if !response.Uncompressed || len(countries.Data) == 0 {
+		t.Fatalf("invalid decoded response: got uncompressed %v with %d countries", response.Uncompressed, len(countries.Data))
+	}
+}
+
+func TestNewApp_requestBody(t *testing.T) {
+	server := newMemoryServer(t, func(config *viper.Viper) {
+		config.Set("request.maxBodySize", 64)
+	})
+	header := login(t, server, "staff")
+
+	body := `{"country_id":100,"author":"` + strings.Repeat("a", 64) + `","city":"Jakarta"}`
+	response := doJSON(t, http.MethodPost, server.URL+"/authors", body, header, nil)
+	if response.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("invalid too large status code: got %d, want 413", response.StatusCode)
+	}
+
+	for _, route := range []string{"POST /authors", "PUT /authors/1", "PUT /books/1", "POST /orders", "POST /orders/1/transitions"} {
+		method, path, _ := strings.Cut(route, " ")
+		response = doJSON(t, method, server.URL+path, "null", header, nil)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Fatalf("invalid null body status code of %s: got %d, want 400", route, response.StatusCode)
+		}
+	}
+
+	header.Set("Content-Type", "text/plain")
+	response = doJSON(t, http.MethodPost, server.URL+"/authors", `{"author":"Tere Liye"}`, header, nil)
+	if response.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("invalid content type status code: got %d, want 415", response.StatusCode)
+	}
+}
+
+func TestNewApp_conditional(t *testing.T) {
+	server := newMemoryServer(t, func(config *viper.Viper) {
+		config.Set("conditional.requireIfMatch", true)
+	})
+	admin := login(t, server, "admin")
+
+	response := doJSON(t, http.MethodGet, server.URL+"/countries/100", "", nil, nil)
+	tag := response.Header.Get("ETag")
+	if response.StatusCode != http.StatusOK || tag == "" {
+		t.Fatalf("invalid get country: got %d with ETag %q", response.StatusCode, tag)
+	}
+
+	response = doJSON(t, http.MethodGet, server.URL+"/countries/100", "", http.Header{"If-None-Match": {tag}}, nil)
+	if response.StatusCode != http.StatusNotModified {
+		t.Fatalf("invalid unchanged get status code: got %d, want 304", response.StatusCode)
+	}
+
+	body := `{"currency":"IDR"}`
+	response = doJSON(t, http.MethodPut, server.URL+"/countries/100", body, admin, nil)
+	if response.StatusCode != http.StatusPreconditionRequired {
+		t.Fatalf("invalid update without If-Match status code: got %d, want 428", response.StatusCode)
+	}
+
+	admin.Set("If-Match", `"0123456789abcdef0123456789abcdef"`)
+	response = doJSON(t, http.MethodPut, server.URL+"/countries/100", body, admin, nil)
+	if response.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("invalid update with another ETag status code: got %d, want 412", response.StatusCode)
+	}
+
+	admin.Set("If-Match", tag)
+	response = doJSON(t, http.MethodPut, server.URL+"/countries/100", body, admin, nil)
+	if response.StatusCode != http.StatusOK || response.Header.Get("ETag") == tag {
+		t.Fatalf("invalid update: got %d with ETag %q", response.StatusCode, response.Header.Get("ETag"))
+	}
+	updatedTag := response.Header.Get("ETag")
+
+	// the tag read before the update is stale
+	response = doJSON(t, http.MethodDelete, server.URL+"/countries/100", "", admin, nil)
+	if response.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("invalid delete with a stale ETag status code: got %d, want 412", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodGet, server.URL+"/countries/100", "", http.Header{"If-None-Match": {tag}}, nil)
+	if response.StatusCode != http.StatusOK || response.Header.Get("ETag") != updatedTag {
+		t.Fatalf("invalid get after update: got %d with ETag %q, want %q", response.StatusCode, response.Header.Get("ETag"), updatedTag)
+	}
+}
+
+func TestNewApp_conditionalAuthor(t *testing.T) {
+	server := newMemoryServer(t, func(config *viper.Viper) {
+		config.Set("conditional.requireIfMatch", true)
+		config.Set("compression.minSize", 1)
+	})
+	admin := login(t, server, "admin")
+
+	var author struct {
+		Data struct {
+			Country_Id uint8
+		}
+	}
+	response := doJSON(t, http.MethodGet, server.URL+"/authors/1", "", http.Header{"Accept-Encoding": {"identity"}}, &author)
+	tag := response.Header.Get("ETag")
+	if response.StatusCode != http.StatusOK || tag == "" {
+		t.Fatalf("invalid get author: got %d with ETag %q", response.StatusCode, tag)
+	}
+
+	// the gzip representation has its own tag, matching the unencoded one
+	gzipHeader := http.Header{"Accept-Encoding": {"gzip"}}
+	response = doJSON(t, http.MethodGet, server.URL+"/authors/1", "", gzipHeader, nil)
+	gzipTag := response.Header.Get("ETag")
+	if response.Header.Get("Content-Encoding") != "gzip" || gzipTag != strings.TrimSuffix(tag, `"`)+`-gzip"` {
+		t.Fatalf("invalid gzip get author: got %q with ETag %q", response.Header.Get("Content-Encoding"), gzipTag)
+	}
+	gzipHeader.Set("If-None-Match", gzipTag)
+	response = doJSON(t, http.MethodGet, server.URL+"/authors/1", "", gzipHeader, nil)
+	if response.StatusCode != http.StatusNotModified {
+		t.Fatalf("invalid unchanged gzip get status code: got %d, want 304", response.StatusCode)
+	}
+
+	// the country of the author changes apart from its tag
+	countryURL := server.URL + "/countries/" + strconv.Itoa(int(author.Data.Country_Id))
+	response = doJSON(t, http.MethodGet, countryURL, "", nil, nil)
+	admin.Set("If-Match", response.Header.Get("ETag"))
+	response = doJSON(t, http.MethodPut, countryURL, `{"currency":"IDR"}`, admin, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid update country status code: got %d, want 200", response.StatusCode)
+	}
+
+	admin.Set("If-Match", gzipTag)
+	response = doJSON(t, http.MethodPut, server.URL+"/authors/1", `{"city":"Bandung"}`, admin, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid update author with the gzip ETag status code: got %d, want 200", response.StatusCode)
+	}
+}
+
+func TestNewApp_orderReplay(t *testing.T) {
+	server := newMemoryServer(t)
+	header := login(t, server, "staff")
+	header.Set("X-Idempotency-Key", "9b2e6f0a-3c4d-4e5f-8a7b-1c2d3e4f5a6b")
+
+	var created struct {
+		Data struct {
+			ID     uint64
+			Status string
+		}
+	}
+	orderBody := `{"books":[{"book_id":2,"quantity":1}]}`
+	response := doJSON(t, http.MethodPost, server.URL+"/orders", orderBody, header, &created)
+	if response.StatusCode != http.StatusOK || created.Data.Status != "processed" {
+		t.Fatalf("invalid create order: got %d %+v", response.StatusCode, created)
+	}
+
+	transitionURL := server.URL + "/orders/" + strconv.FormatUint(created.Data.ID, 10) + "/transitions"
+	response = doJSON(t, http.MethodPost, transitionURL, `{"status":"cancelled"}`, header, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid cancel order status code: got %d, want 200", response.StatusCode)
+	}
+
+	// the replay answers the original create, not the cancelled order
+	var replayed struct {
+		Data struct {
+			ID     uint64
+			Status string
+		}
+	}
+	response = doJSON(t, http.MethodPost, server.URL+"/orders", orderBody, header, &replayed)
+	if response.StatusCode != http.StatusOK || response.Header.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("invalid replayed order: got %d %v", response.StatusCode, response.Header)
+	}
+	if replayed.Data != created.Data {
+		t.Fatalf("invalid replayed order: got %+v, want %+v", replayed.Data, created.Data)
+	}
+
+	// the key belongs to the staff user: another client neither replays
+	// its order nor places one with it
+	customer := login(t, server, "customer")
+	customer.Set("X-Idempotency-Key", header.Get("X-Idempotency-Key"))
+	var body struct {
+		Data json.RawMessage
+	}
+	response = doJSON(t, http.MethodPost, server.URL+"/orders", orderBody, customer, &body)
+	if response.StatusCode != http.StatusConflict || response.Header.Get("Idempotent-Replayed") != "" || len(body.Data) != 0 {
+		t.Fatalf("invalid create order with the key of another client: got %d %v %s, want 409", response.StatusCode, response.Header, body.Data)
+	}
+}
+
+func TestNewApp_orderTransitionChangedBy(t *testing.T) {
+	server := newMemoryServer(t)
+	header := login(t, server, "staff")
+	header.Set("X-Idempotency-Key", "5d6e7f80-9a1b-4c2d-8e3f-4a5b6c7d8e9f")
+
+	var created struct {
+		Data struct{ ID uint64 }
+	}
+	doJSON(t, http.MethodPost, server.URL+"/orders", `{"books":[{"book_id":1,"quantity":1}]}`, header, &created)
+	transitionURL := server.URL + "/orders/" + strconv.FormatUint(created.Data.ID, 10) + "/transitions"
+
+	response := doJSON(t, http.MethodPost, transitionURL, `{"status":"shipped","changed_by":"admin"}`, header, nil)
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid transition with changed_by status code: got %d, want 400", response.StatusCode)
+	}
+
+	response = doJSON(t, http.MethodPost, transitionURL, `{"status":"shipped"}`, header, nil)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid transition status code: got %d, want 200", response.StatusCode)
+	}
+
+	var histories struct {
+		Data []struct {
+			To_Status  string
+			Changed_By *string
+		}
+	}
+	doJSON(t, http.MethodGet, transitionURL, "", header, &histories)
+	last := histories.Data[len(histories.Data)-1]
+	if last.To_Status != "shipped" || last.Changed_By == nil || *last.Changed_By != "staff" {
+		t.Fatalf("invalid transition history: got %+v", histories.Data)
+	}
+}
+
+func TestNewApp_authorizeOrders(t *testing.T) {
+	server := newMemoryServer(t)
+
+	header := login(t, server, "customer")
+	header.Set("X-Idempotency-Key", "0e1f2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b")
+	var created struct {
+		Data struct{ ID uint64 }
+	}
+	response := doJSON(t, http.MethodPost, server.URL+"/orders", `{"books":[{"book_id":1,"quantity":1}]}`, header, &created)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("invalid customer create order status code: got %d, want 200", response.StatusCode)
+	}
+	transitionURL := server.URL + "/orders/" + strconv.FormatUint(created.Data.ID, 10) + "/transitions"
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"customer", login(t, server, "customer"), http.StatusForbidden},
+		{"staff", login(t, server, "staff"), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := doJSON(t, http.MethodPost, transitionURL, `{"status":"cancelled"}`, tt.header, nil)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("invalid transition status code: got %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	response = doJSON(t, http.MethodGet, server.URL+"/orders/"+strconv.FormatUint(created.Data.ID, 10), "", nil, nil)
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("invalid anonymous get order status code: got %d, want 401", response.StatusCode)
+	}
+}
+
+func TestNewApp_readOwnOrders(t *testing.T) {
+	server := newMemoryServer(t)
+
+	orderURL := func(header http.Header, key string) string {
+		header.Set("X-Idempotency-Key", key)
+		var created struct {
+			Data struct{ ID uint64 }
+		}
+		response := doJSON(t, http.MethodPost, server.URL+"/orders", `{"books":[{"book_id":1,"quantity":1}]}`, header, &created)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("invalid create order status code: got %d, want 200", response.StatusCode)
+		}
+		return server.URL + "/orders/" + strconv.FormatUint(created.Data.ID, 10)
+	}
+	customer := login(t, server, "customer")
+	staff := login(t, server, "staff")
+	customerOrder := orderURL(customer, "6a7b8c9d-0e1f-4a2b-8c3d-4e5f6a7b8c9d")
+	staffOrder := orderURL(staff, "7b8c9d0e-1f2a-4b3c-8d4e-5f6a7b8c9d0e")
+
+	tests := []struct {
+		name       string
+		url        string
+		header     http.Header
+		wantStatus int
+	}{
+		{"customer own order", customerOrder, customer, http.StatusOK},
+		{"customer own transitions", customerOrder + "/transitions", customer, http.StatusOK},
+		{"customer other order", staffOrder, customer, http.StatusNotFound},
+		{"customer other transitions", staffOrder + "/transitions", customer, http.StatusNotFound},
+		{"staff other order", customerOrder, staff, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Data json.RawMessage
+			}
+			response := doJSON(t, http.MethodGet, tt.url, "", tt.header, &body)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("invalid get order status code: got %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusNotFound && len(body.Data) != 0 {
+				t.Fatalf("invalid get order of another client: got %s", body.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"toko-buku-api/pkg/auth"
+
+	"github.com/spf13/viper"
+)
+
+// secretPlaceholder is the HS256 secret config.json once shipped with, known
+// to anyone reading the repository
+const secretPlaceholder = "change-me-toko-buku-api-dev-secret"
+
+// NewJWT builds the signer of the access tokens from the auth section of
+// config.json, with a shared secret for HS256 or PEM key files for RS256.
+// The secret comes from the TOKO_BUKU_AUTH_SECRET environment variable or
+// the file named by auth.secretFile, and must be set
+func NewJWT(config *viper.Viper) (*auth.JWT, error) {
+	issuer := config.GetString("auth.issuer")
+	ttl := time.Duration(config.GetInt("auth.tokenTTL")) * time.Minute
+
+	switch algorithm := config.GetString("auth.algorithm"); algorithm {
+	case auth.HS256:
+		secret := config.GetString("auth.secret")
+		if file := config.GetString("auth.secretFile"); file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			secret = strings.TrimSpace(string(data))
+		}
+		if secret == "" || secret == secretPlaceholder {
+			return nil, errors.New("auth.secret is not set: set TOKO_BUKU_AUTH_SECRET or auth.secretFile")
+		}
+
+		return auth.NewHS256([]byte(secret), issuer, ttl)
+	case auth.RS256:
+		var privateKey *rsa.PrivateKey
+		if file := config.GetString("auth.privateKeyFile"); file != "" {
+			key, err := readPEM(file)
+			if err != nil {
+				return nil, err
+			}
+			if privateKey, err = parsePrivateKey(key); err != nil {
+				return nil, fmt.Errorf("private key %s: %w", file, err)
+			}
+		}
+
+		var publicKey *rsa.PublicKey
+		if file := config.GetString("auth.publicKeyFile"); file != "" {
+			key, err := readPEM(file)
+			if err != nil {
+				return nil, err
+			}
+			if publicKey, err = parsePublicKey(key); err != nil {
+				return nil, fmt.Errorf("public key %s: %w", file, err)
+			}
+		}
+
+		return auth.NewRS256(privateKey, publicKey, issuer, ttl)
+	default:
+		return nil, fmt.Errorf("unsupported auth.algorithm %q", algorithm)
+	}
+}
+
+// NewStaticUsers returns the users allowed to log in, listed with their role
+// and the bcrypt hash of their password under auth.users, and under users in
+// the file named by auth.usersFile, a secret kept out of the repository
+func NewStaticUsers(config *viper.Viper) (auth.StaticUsers, error) {
+	var users auth.StaticUsers
+	if err := config.UnmarshalKey("auth.users", &users); err != nil {
+		return nil, err
+	}
+
+	if file := config.GetString("auth.usersFile"); file != "" {
+		usersConfig := viper.New()
+		usersConfig.SetConfigFile(file)
+		if err := usersConfig.ReadInConfig(); err != nil {
+			return nil, err
+		}
+
+		var fileUsers auth.StaticUsers
+		if err := usersConfig.UnmarshalKey("users", &fileUsers); err != nil {
+			return nil, fmt.Errorf("users file %s: %w", file, err)
+		}
+		users = append(users, fileUsers...)
+	}
+
+	return users, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", file)
+	}
+
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+func parsePublicKey(block *pem.Block) (*rsa.PublicKey, error) {
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+
+	return rsaKey, nil
+}
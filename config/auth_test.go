@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestNewJWT_secret(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secretFile, []byte(testSecret+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write the secret file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		set     map[string]any
+		wantErr bool
+	}{
+		{"missing", "", nil, true},
+		{"placeholder", "", map[string]any{"auth.secret": secretPlaceholder}, true},
+		{"environment", testSecret, nil, false},
+		{"placeholder environment", secretPlaceholder, nil, true},
+		{"file", "", map[string]any{"auth.secretFile": secretFile}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TOKO_BUKU_AUTH_SECRET", tt.env)
+			config := viper.New()
+			bindEnv(config)
+			setDefaults(config)
+			for key, value := range tt.set {
+				config.Set(key, value)
+			}
+
+			_, err := NewJWT(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("invalid error: got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewStaticUsers_file(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users.json")
+	data := `{"users":[{"username":"admin","passwordHash":"` + testPasswordHash + `","role":"admin"}]}`
+	if err := os.WriteFile(usersFile, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write the users file: %v", err)
+	}
+
+	config := viper.New()
+	config.Set("auth.usersFile", usersFile)
+	users, err := NewStaticUsers(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || users[0].Username != "admin" || users[0].Role != "admin" {
+		t.Fatalf("invalid users: got %+v", users)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"compress/flate"
+	"fmt"
+	"toko-buku-api/pkg/web"
+
+	"github.com/spf13/viper"
+)
+
+// NewCompressOptions returns the minimum size and the level of the response
+// compression set under compression in config.json
+func NewCompressOptions(config *viper.Viper) (web.CompressOptions, error) {
+	options := web.CompressOptions{
+		MinSize: config.GetInt("compression.minSize"),
+		Level:   config.GetInt("compression.level"),
+	}
+	if options.Level < flate.HuffmanOnly || options.Level > flate.BestCompression {
+		return options, fmt.Errorf("compression.level %d out of range [%d, %d]", options.Level, flate.HuffmanOnly, flate.BestCompression)
+	}
+
+	return options, nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"time"
+	"toko-buku-api/pkg/web"
+
+	"github.com/spf13/viper"
+)
+
+// NewCORSOptions returns the cross-origin requests allowed under cors in
+// config.json, none without allowedOrigins
+func NewCORSOptions(config *viper.Viper) web.CORSOptions {
+	return web.CORSOptions{
+		AllowedOrigins:   config.GetStringSlice("cors.allowedOrigins"),
+		AllowedMethods:   config.GetStringSlice("cors.allowedMethods"),
+		AllowedHeaders:   config.GetStringSlice("cors.allowedHeaders"),
+		ExposedHeaders:   config.GetStringSlice("cors.exposedHeaders"),
+		AllowCredentials: config.GetBool("cors.allowCredentials"),
+		MaxAge:           time.Duration(config.GetInt("cors.maxAge")) * time.Second,
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"os"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/web"
+)
+
+// NewLogger constructs the logger of a service, adding the trace id of the
+// request to every line logged with its context
+func NewLogger(serviceName string) *logger.Logger {
+	return logger.New(os.Stdout, logger.LevelDebug, serviceName, web.GetTraceID)
+}
+
+// NewLoggerWithEvents constructs the logger of a service like NewLogger,
+// running the events for the levels they are set for
+func NewLoggerWithEvents(serviceName string, events logger.Events) *logger.Logger {
+	return logger.NewWithEvents(os.Stdout, logger.LevelDebug, serviceName, web.GetTraceID, events)
+}
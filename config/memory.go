@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+	"time"
+	"toko-buku-api/internal/apikeys"
+	"toko-buku-api/internal/authors"
+	"toko-buku-api/internal/books"
+	"toko-buku-api/internal/countries"
+	"toko-buku-api/internal/orders"
+	"toko-buku-api/internal/roles"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/internal/users"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// Database drivers selectable with `database.driver` in config.json
+const (
+	DriverMySQL  = "mysql"
+	DriverMemory = "memory"
+)
+
+// NewMemoryDatabase creates the in-memory tables in foreign key order and
+// seeds them with the rows of db/migrations, for running without MySQL. The
+// tests check the roles against the grants of the migrations
+func NewMemoryDatabase(log *logger.Logger) *memory.DB {
+	db := memory.New()
+	countries.CreateMemoryTables(db)
+	authors.CreateMemoryTables(db)
+	types.CreateMemoryTables(db)
+	books.CreateMemoryTables(db)
+	orders.CreateMemoryTables(db)
+	roles.CreateMemoryTables(db)
+	users.CreateMemoryTables(db)
+	apikeys.CreateMemoryTables(db)
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatal(ctx, "failed to begin memory database seed", "error", err)
+	}
+
+	seededAt := time.Date(2024, time.December, 2, 17, 22, 47, 0, time.Local)
+	typeId := uint16(1)
+	rows := []struct {
+		table string
+		id    uint64
+		row   any
+	}{
+		{countries.MemoryTable, 100, countries.Countries{ID: 100, Iso3: "IDN", Country: "INDONESIA", Nice_Country: "Indonesia", Currency: "Rp"}},
+		{countries.MemoryTable, 225, countries.Countries{ID: 225, Iso3: "GBR", Country: "UNITED KINGDOM", Nice_Country: "United Kingdom", Currency: "£"}},
+		{countries.MemoryTable, 226, countries.Countries{ID: 226, Iso3: "USA", Country: "UNITED STATES", Nice_Country: "United States", Currency: "$"}},
+		{authors.MemoryTable, 1, authors.Authors{ID: 1, Updated_At: seededAt, Country_Id: 100, Author: "Buya Hamka", City: "Sumatera Barat, Indonesia"}},
+		{authors.MemoryTable, 2, authors.Authors{ID: 2, Updated_At: seededAt, Country_Id: 100, Author: "Pramoedya Ananta Toer", City: "Jawa Timur, Indonesia"}},
+		{types.MemoryTable, 1, types.Types{ID: 1, Updated_At: &seededAt, Type: "novel"}},
+		{books.MemoryTable, 1, books.Books{ID: 1, Created_At: seededAt, Author_Id: 1, Type_Id: &typeId, Title: "Tenggelamnya Kapal van der Wijck", Sku: "kapal-van-der_1", Price: 6.45, Stock: 100}},
+		{roles.MemoryTable, 1, roles.Roles{ID: 1, Name: "admin", Permissions: []string{"apikeys:read", "apikeys:write", "authors:delete", "authors:write", "books:delete", "books:write", "countries:delete", "countries:write", "orders:read", "orders:read-own", "orders:transition", "orders:write", "types:delete", "types:write"}}},
+		{roles.MemoryTable, 2, roles.Roles{ID: 2, Name: "staff", Permissions: []string{"authors:delete", "authors:write", "books:delete", "books:write", "orders:read", "orders:transition", "orders:write", "types:delete", "types:write"}}},
+		{roles.MemoryTable, 3, roles.Roles{ID: 3, Name: "customer", Permissions: []string{"orders:read-own", "orders:write"}}},
+		{books.MemoryTable, 2, books.Books{ID: 2, Created_At: seededAt, Author_Id: 2, Type_Id: &typeId, Title: "Bumi Manusia", Sku: "bumi-manusia_1", Price: 8.20, Stock: 100}},
+	}
+
+	for _, seed := range rows {
+		if err := memory.Of(tx).Put(seed.table, seed.id, seed.row); err != nil {
+			tx.Rollback()
+			log.Fatal(ctx, "failed to seed memory database", "error", err, "table", seed.table)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatal(ctx, "failed to commit memory database seed", "error", err)
+	}
+
+	return db
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"io/fs"
+	"regexp"
+	"slices"
+	"testing"
+	"toko-buku-api/db/migrations"
+	"toko-buku-api/internal/roles"
+	"toko-buku-api/pkg/auth"
+)
+
+var (
+	roleInsert           = regexp.MustCompile(`INSERT INTO role \(id, name\) VALUES \((\d+), '([^']+)'\)`)
+	permissionInsert     = regexp.MustCompile(`INSERT INTO permission \(id, name\) VALUES \((\d+), '([^']+)'\)`)
+	rolePermissionInsert = regexp.MustCompile(`INSERT INTO role_has_permission \(role_id, permission_id\) VALUES ([^;]+) ON DUPLICATE`)
+	rolePermissionValues = regexp.MustCompile(`\((\d+), (\d+)\)`)
+)
+
+// migrationPolicy reads the roles and the permissions they grant from the
+// seeds of the up migrations, in version order
+func migrationPolicy(t *testing.T) (auth.Policy, []auth.Permission) {
+	names, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		t.Fatalf("failed to list the migrations: %v", err)
+	}
+
+	roleNames := map[string]string{}
+	permissionNames := map[string]auth.Permission{}
+	var grants [][2]string
+	for _, name := range names {
+		content, err := fs.ReadFile(migrations.FS, name)
+		if err != nil {
+			t.Fatalf("failed to read migration %s: %v", name, err)
+		}
+		for _, match := range roleInsert.FindAllStringSubmatch(string(content), -1) {
+			roleNames[match[1]] = match[2]
+		}
+		for _, match := range permissionInsert.FindAllStringSubmatch(string(content), -1) {
+			permissionNames[match[1]] = auth.Permission(match[2])
+		}
+		for _, insert := range rolePermissionInsert.FindAllStringSubmatch(string(content), -1) {
+			for _, match := range rolePermissionValues.FindAllStringSubmatch(insert[1], -1) {
+				grants = append(grants, [2]string{match[1], match[2]})
+			}
+		}
+	}
+
+	policy := auth.Policy{}
+	for _, name := range roleNames {
+		policy[name] = []auth.Permission{}
+	}
+	for _, grant := range grants {
+		role, ok := roleNames[grant[0]]
+		if !ok {
+			t.Fatalf("invalid migration grant: role %s is not inserted", grant[0])
+		}
+		permission, ok := permissionNames[grant[1]]
+		if !ok {
+			t.Fatalf("invalid migration grant: permission %s is not inserted", grant[1])
+		}
+		policy[role] = append(policy[role], permission)
+	}
+
+	permissions := make([]auth.Permission, 0, len(permissionNames))
+	for _, permission := range permissionNames {
+		permissions = append(permissions, permission)
+	}
+
+	return policy, permissions
+}
+
+func TestNewMemoryDatabase_roles(t *testing.T) {
+	want, permissions := migrationPolicy(t)
+	if len(want) == 0 {
+		t.Fatal("invalid migrations: no role inserted")
+	}
+
+	log := NewLogger("TEST")
+	db := NewMemoryDatabase(log)
+	roleUsecase := roles.NewUsecase(roles.NewMemoryRepository(log), db, log)
+	got, err := roleUsecase.GetPolicy(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get the memory policy: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("invalid memory roles: got %v, want %v", got, want)
+	}
+	for role, wantPermissions := range want {
+		gotPermissions := slices.Clone(got[role])
+		slices.Sort(gotPermissions)
+		slices.Sort(wantPermissions)
+		if !slices.Equal(gotPermissions, wantPermissions) {
+			t.Fatalf("invalid memory permissions of role %s: got %v, want %v", role, gotPermissions, wantPermissions)
+		}
+	}
+
+	// the permissions of the routes are the rows of the permission table
+	slices.Sort(permissions)
+	routePermissions := slices.Clone(auth.Permissions)
+	slices.Sort(routePermissions)
+	if !slices.Equal(permissions, routePermissions) {
+		t.Fatalf("invalid migration permissions: got %v, want %v", permissions, routePermissions)
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"toko-buku-api/pkg/ratelimit"
+
+	"github.com/spf13/viper"
+)
+
+// rateLimitGroup is a route group of rateLimit.groups in config.json
+type rateLimitGroup struct {
+	RequestsPerMinute float64 `mapstructure:"requestsPerMinute"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// NewRateLimits returns the limits of the route groups listed under
+// rateLimit.groups, keyed by the first segment of their paths, the default
+// group applying to the others
+func NewRateLimits(config *viper.Viper) (map[string]ratelimit.Limit, error) {
+	var groups map[string]rateLimitGroup
+	if err := config.UnmarshalKey("rateLimit.groups", &groups); err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]ratelimit.Limit, len(groups))
+	for name, group := range groups {
+		limits[name] = ratelimit.PerMinute(group.RequestsPerMinute, group.Burst)
+	}
+
+	return limits, nil
+}
+
+// NewFailedAuthLimit returns the limit of the failed authentications of
+// every ip, set by rateLimit.failedAuth
+func NewFailedAuthLimit(config *viper.Viper) (ratelimit.Limit, error) {
+	var group rateLimitGroup
+	if err := config.UnmarshalKey("rateLimit.failedAuth", &group); err != nil {
+		return ratelimit.Limit{}, err
+	}
+
+	return ratelimit.PerMinute(group.RequestsPerMinute, group.Burst), nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"toko-buku-api/internal/apikeys"
+	"toko-buku-api/internal/authors"
+	"toko-buku-api/internal/books"
+	"toko-buku-api/internal/countries"
+	"toko-buku-api/internal/orders"
+	"toko-buku-api/internal/roles"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/internal/users"
+	"toko-buku-api/pkg/schema"
+)
+
+// VerifySchema compares the tables and columns every MySQL repository
+// queries against the database, so a database that was not migrated fails
+// at startup instead of on the first request
+func VerifySchema(ctx context.Context, db *sql.DB) error {
+	var tables []schema.Table
+	for _, repositorySchema := range [][]schema.Table{
+		countries.Schema,
+		authors.Schema,
+		types.Schema,
+		books.Schema,
+		orders.Schema,
+		roles.Schema,
+		users.Schema,
+		apikeys.Schema,
+	} {
+		tables = append(tables, repositorySchema...)
+	}
+
+	return schema.Verify(ctx, db, tables)
+}
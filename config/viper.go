@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -15,6 +16,8 @@ func NewViper() *viper.Viper {
 	config.SetConfigType("json")
 	config.AddConfigPath("./../")
 	config.AddConfigPath("./")
+	bindEnv(config)
+	setDefaults(config)
 	err := config.ReadInConfig()
 
 	if err != nil {
@@ -23,3 +26,31 @@ func NewViper() *viper.Viper {
 
 	return config
 }
+
+// bindEnv lets the environment override the keys, like the secrets kept out
+// of config.json: auth.secret is read from TOKO_BUKU_AUTH_SECRET
+func bindEnv(config *viper.Viper) {
+	config.SetEnvPrefix("TOKO_BUKU")
+	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	config.AutomaticEnv()
+}
+
+// setDefaults sets the values of the keys config.json may leave out
+func setDefaults(config *viper.Viper) {
+	config.SetDefault("log.access.sampleRate", 1.0)
+	config.SetDefault("auth.algorithm", "HS256")
+	config.SetDefault("auth.issuer", "toko-buku-api")
+	config.SetDefault("auth.tokenTTL", 60)
+	config.SetDefault("cors.allowedMethods", []string{"GET", "POST", "PUT", "DELETE"})
+	config.SetDefault("cors.allowedHeaders", []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "X-API-Key", "X-Idempotency-Key", "X-Request-ID", "If-Match", "If-None-Match"})
+	config.SetDefault("cors.exposedHeaders", []string{"ETag", "X-Request-ID", "Idempotent-Replayed", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"})
+	config.SetDefault("cors.maxAge", 600)
+	config.SetDefault("request.maxBodySize", 1<<20)
+	config.SetDefault("query.cursorSecret", "")
+	config.SetDefault("conditional.requireIfMatch", false)
+	config.SetDefault("mail.devLogTokens", false)
+	config.SetDefault("compression.minSize", 1024)
+	config.SetDefault("compression.level", -1)
+	config.SetDefault("rateLimit.failedAuth.requestsPerMinute", 10)
+	config.SetDefault("rateLimit.failedAuth.burst", 10)
+}
@@ -6,7 +6,6 @@ CREATE TABLE IF NOT EXISTS `order` (
     x_idempotency_key VARCHAR(38) NOT NULL COMMENT 'Idempotency  http POST.\n\nHTTP Header:\nx-idempotency-key=….',
     status ENUM("processed", "received") NOT NULL DEFAULT 'processed',
     count TINYINT UNSIGNED NOT NULL DEFAULT 1 COMMENT 'Min.: 1 pc.\nMax.: 15 pcs.',
-    PRIMARY KEY (id)
+    PRIMARY KEY (id),
+    UNIQUE INDEX `idempotency_key_UNIQUE` (`x_idempotency_key` ASC) VISIBLE
 ) ENGINE = InnoDB;
-
-CREATE UNIQUE INDEX `idempotency_key_UNIQUE` ON `toko-buku-api`.`order` (`x-idempotency-key` ASC) VISIBLE;
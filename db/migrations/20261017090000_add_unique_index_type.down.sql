@@ -0,0 +1 @@
+DROP INDEX type_UNIQUE ON `type`;
\ No newline at end of file
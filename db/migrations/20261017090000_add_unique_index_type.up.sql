@@ -0,0 +1 @@
+CREATE UNIQUE INDEX type_UNIQUE ON `type` (`type` ASC);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE order_has_book DROP COLUMN quantity;
\ No newline at end of file
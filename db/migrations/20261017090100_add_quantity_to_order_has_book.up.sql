@@ -0,0 +1 @@
+ALTER TABLE order_has_book ADD COLUMN quantity TINYINT UNSIGNED NOT NULL DEFAULT 1 COMMENT 'Min.: 1 pc.\nMax.: 15 pcs.' AFTER book_id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_status_history;
+
+UPDATE `order` SET status = 'processed' WHERE status IN ("shipped", "cancelled");
+
+ALTER TABLE `order` MODIFY status ENUM("processed", "received") NOT NULL DEFAULT 'processed';
\ No newline at end of file
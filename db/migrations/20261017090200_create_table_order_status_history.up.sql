@@ -0,0 +1,17 @@
+ALTER TABLE `order` MODIFY status ENUM("processed", "shipped", "received", "cancelled") NOT NULL DEFAULT 'processed';
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    order_id BIGINT UNSIGNED NOT NULL,
+    from_status ENUM("processed", "shipped", "received", "cancelled") NULL,
+    to_status ENUM("processed", "shipped", "received", "cancelled") NOT NULL,
+    changed_by VARCHAR(100) NULL,
+    note VARCHAR(255) NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_order_status_history_order
+        FOREIGN KEY (order_id)
+        REFERENCES `order` (id)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE)
+ENGINE = InnoDB;
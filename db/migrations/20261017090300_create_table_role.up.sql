@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS role (
+    id TINYINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(20) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX name_UNIQUE (name ASC)
+) ENGINE = InnoDB;
+
+START TRANSACTION;
+
+INSERT INTO role (id, name) VALUES (1, 'admin') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO role (id, name) VALUES (2, 'staff') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO role (id, name) VALUES (3, 'customer') ON DUPLICATE KEY UPDATE name=name;
+
+COMMIT;
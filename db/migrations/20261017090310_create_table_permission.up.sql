@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS permission (
+    id SMALLINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(50) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX name_UNIQUE (name ASC)
+) ENGINE = InnoDB;
+
+START TRANSACTION;
+
+INSERT INTO permission (id, name) VALUES (1, 'authors:write') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (2, 'authors:delete') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (3, 'books:write') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (4, 'books:delete') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (5, 'types:write') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (6, 'types:delete') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (7, 'countries:write') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (8, 'countries:delete') ON DUPLICATE KEY UPDATE name=name;
+
+COMMIT;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS role_has_permission (
+    role_id TINYINT UNSIGNED NOT NULL,
+    permission_id SMALLINT UNSIGNED NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_has_permission_role
+        FOREIGN KEY (role_id)
+        REFERENCES role (id)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE,
+    CONSTRAINT fk_role_has_permission_permission
+        FOREIGN KEY (permission_id)
+        REFERENCES permission (id)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE)
+ENGINE = InnoDB;
+
+START TRANSACTION;
+
+INSERT INTO role_has_permission (role_id, permission_id) VALUES (1, 1), (1, 2), (1, 3), (1, 4), (1, 5), (1, 6), (1, 7), (1, 8) ON DUPLICATE KEY UPDATE role_id=role_id;
+INSERT INTO role_has_permission (role_id, permission_id) VALUES (2, 1), (2, 2), (2, 3), (2, 4), (2, 5), (2, 6) ON DUPLICATE KEY UPDATE role_id=role_id;
+
+COMMIT;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `user` (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    email VARCHAR(254) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role_id TINYINT UNSIGNED NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX email_UNIQUE (email ASC),
+    CONSTRAINT fk_user_role
+        FOREIGN KEY (role_id)
+        REFERENCES role (id)
+        ON DELETE RESTRICT
+        ON UPDATE CASCADE)
+ENGINE = InnoDB;
@@ -0,0 +1 @@
+ALTER TABLE `user` DROP COLUMN locked_until, DROP COLUMN failed_logins;
@@ -0,0 +1 @@
+ALTER TABLE `user` ADD COLUMN failed_logins TINYINT UNSIGNED NOT NULL DEFAULT 0 AFTER role_id, ADD COLUMN locked_until TIMESTAMP NULL AFTER failed_logins;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_key (
+    id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(100) NOT NULL,
+    prefix CHAR(8) NOT NULL,
+    secret_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(500) NOT NULL COMMENT 'Comma separated permissions',
+    created_by VARCHAR(100) NOT NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX prefix_UNIQUE (prefix ASC)
+) ENGINE = InnoDB;
@@ -0,0 +1 @@
+DELETE FROM permission WHERE id IN (9, 10);
@@ -0,0 +1,8 @@
+START TRANSACTION;
+
+INSERT INTO permission (id, name) VALUES (9, 'apikeys:read') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (10, 'apikeys:write') ON DUPLICATE KEY UPDATE name=name;
+
+INSERT INTO role_has_permission (role_id, permission_id) VALUES (1, 9), (1, 10) ON DUPLICATE KEY UPDATE role_id=role_id;
+
+COMMIT;
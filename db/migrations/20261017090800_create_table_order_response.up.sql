@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS order_response (
+    order_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status SMALLINT UNSIGNED NOT NULL COMMENT 'Http status of the create, replayed with the idempotency key.',
+    body MEDIUMBLOB NOT NULL COMMENT 'Data of the create response, replayed byte for byte.',
+    PRIMARY KEY (order_id),
+    CONSTRAINT fk_order_response_order
+        FOREIGN KEY (order_id)
+        REFERENCES `order` (id)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE)
+ENGINE = InnoDB;
@@ -0,0 +1 @@
+DELETE FROM permission WHERE id IN (11, 12, 13);
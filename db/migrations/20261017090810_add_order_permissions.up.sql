@@ -0,0 +1,9 @@
+START TRANSACTION;
+
+INSERT INTO permission (id, name) VALUES (11, 'orders:read') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (12, 'orders:write') ON DUPLICATE KEY UPDATE name=name;
+INSERT INTO permission (id, name) VALUES (13, 'orders:transition') ON DUPLICATE KEY UPDATE name=name;
+
+INSERT INTO role_has_permission (role_id, permission_id) VALUES (1, 11), (1, 12), (1, 13), (2, 11), (2, 12), (2, 13), (3, 12) ON DUPLICATE KEY UPDATE role_id=role_id;
+
+COMMIT;
@@ -0,0 +1,4 @@
+ALTER TABLE `order`
+    DROP INDEX `created_by_idempotency_key_UNIQUE`,
+    ADD UNIQUE INDEX `idempotency_key_UNIQUE` (`x_idempotency_key` ASC) VISIBLE,
+    DROP COLUMN created_by;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE `order`
+    ADD COLUMN created_by VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'Subject of the principal placing the order, owning its idempotency key.' AFTER deleted_at,
+    DROP INDEX `idempotency_key_UNIQUE`,
+    ADD UNIQUE INDEX `created_by_idempotency_key_UNIQUE` (`created_by` ASC, `x_idempotency_key` ASC) VISIBLE;
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM permission WHERE id = 14;
\ No newline at end of file
@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+INSERT INTO permission (id, name) VALUES (14, 'orders:read-own') ON DUPLICATE KEY UPDATE name=name;
+
+INSERT INTO role_has_permission (role_id, permission_id) VALUES (1, 14), (3, 14) ON DUPLICATE KEY UPDATE role_id=role_id;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the database, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+package migrations
+
+import "embed"
+
+// FS holds every migration file.
+//
+//go:embed *.sql
+var FS embed.FS
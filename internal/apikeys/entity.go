@@ -0,0 +1,32 @@
+package apikeys
+
+import "time"
+
+// Data models and structs specific to API key functionality
+
+// APIKeys represents the API key of a machine client, stored as its prefix
+// and the sha256 hash of its secret
+type APIKeys struct {
+	ID           uint32     `json:"id"`
+	Created_At   time.Time  `json:"created_at"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	Secret_Hash  string     `json:"-"`
+	Scopes       []string   `json:"scopes"`
+	Created_By   string     `json:"created_by"`
+	Last_Used_At *time.Time `json:"last_used_at"`
+	Revoked_At   *time.Time `json:"revoked_at"`
+}
+
+// CreatedAPIKeys is a created API key with the key itself, which is shown
+// only once
+type CreatedAPIKeys struct {
+	APIKeys
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string   `validate:"required,min=3,max=100" json:"name"`
+	Scopes     []string `validate:"required,min=1,dive,required" json:"scopes"`
+	Created_By string   `json:"-"`
+}
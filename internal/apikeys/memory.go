@@ -0,0 +1,105 @@
+package apikeys
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for API key data
+
+// MemoryTable is the in-memory table of API keys, named after the migration
+const MemoryTable = "api_key"
+
+// CreateMemoryTables adds the API key table to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, nil)
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetAPIKeys(ctx context.Context, tx store.Tx) ([]APIKeys, error) {
+	apiKeys, err := memory.Rows[APIKeys](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetAPIKeys")
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r MemoryRepository) GetAPIKeyById(ctx context.Context, tx store.Tx, apiKeyId uint32) (*APIKeys, error) {
+	funcName := "memory.GetAPIKeyById"
+
+	apiKey, ok, err := memory.Get[APIKeys](memory.Of(tx), MemoryTable, uint64(apiKeyId))
+	if err != nil {
+		r.Log.Error(ctx, "get row with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(apiKeyBaseError, apiKeyId, err)
+	}
+	if !ok {
+		r.Log.Warn(ctx, fmt.Sprintf(apiKeyNotFoundError, apiKeyId), "func_name", funcName)
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return &apiKey, nil
+}
+
+func (r MemoryRepository) GetAPIKeyByPrefix(ctx context.Context, tx store.Tx, prefix string) (*APIKeys, error) {
+	apiKeys, err := r.GetAPIKeys(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, apiKey := range apiKeys {
+		if apiKey.Prefix == prefix {
+			return &apiKey, nil
+		}
+	}
+
+	return nil, ErrAPIKeyNotFound
+}
+
+func (r MemoryRepository) CreateAPIKey(ctx context.Context, tx store.Tx, apiKey *APIKeys) (*APIKeys, error) {
+	apiKey.Created_At = time.Now()
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *apiKey
+		created.ID = uint32(id)
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create api key error", "error", err, "func_name", "memory.CreateAPIKey")
+		return nil, err
+	}
+
+	apiKey.ID = uint32(id)
+	return apiKey, nil
+}
+
+func (r MemoryRepository) RevokeAPIKey(ctx context.Context, tx store.Tx, apiKey *APIKeys) error {
+	return r.put(ctx, tx, apiKey, "memory.RevokeAPIKey")
+}
+
+func (r MemoryRepository) UpdateLastUsed(ctx context.Context, tx store.Tx, apiKey *APIKeys) error {
+	return r.put(ctx, tx, apiKey, "memory.UpdateLastUsed")
+}
+
+func (r MemoryRepository) put(ctx context.Context, tx store.Tx, apiKey *APIKeys, funcName string) error {
+	if err := memory.Of(tx).Put(MemoryTable, uint64(apiKey.ID), *apiKey); err != nil {
+		r.Log.Error(ctx, "put row with update api key error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
+)
+
+// Database access methods for API key data
+
+// Repository is the API key data access used by the usecase
+type Repository interface {
+	GetAPIKeys(ctx context.Context, tx store.Tx) ([]APIKeys, error)
+	GetAPIKeyById(ctx context.Context, tx store.Tx, apiKeyId uint32) (*APIKeys, error)
+	GetAPIKeyByPrefix(ctx context.Context, tx store.Tx, prefix string) (*APIKeys, error)
+	CreateAPIKey(ctx context.Context, tx store.Tx, apiKey *APIKeys) (*APIKeys, error)
+	RevokeAPIKey(ctx context.Context, tx store.Tx, apiKey *APIKeys) error
+	UpdateLastUsed(ctx context.Context, tx store.Tx, apiKey *APIKeys) error
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
+	DB  *sql.DB
+	Log *logger.Logger
+}
+
+const (
+	apiKeyBaseError     = "api key %d: %v"
+	apiKeyNotFoundError = "api key %d: not found"
+)
+
+var (
+	ErrAPIKeyNotFound = errs.NotFound("api key not found")
+	ErrInvalidAPIKey  = errs.Unauthorized("invalid api key")
+)
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "api_key", Columns: []string{"id", "created_at", "name", "prefix", "secret_hash", "scopes", "created_by", "last_used_at", "revoked_at"}},
+}
+
+const selectAPIKey = "SELECT id, created_at, name, prefix, secret_hash, scopes, created_by, last_used_at, revoked_at FROM api_key"
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// scanAPIKey scans a row of selectAPIKey, splitting the comma separated
+// scopes
+func scanAPIKey(scan func(dest ...any) error) (*APIKeys, error) {
+	var apiKey APIKeys
+	var scopes string
+	err := scan(&apiKey.ID, &apiKey.Created_At, &apiKey.Name, &apiKey.Prefix, &apiKey.Secret_Hash, &scopes, &apiKey.Created_By, &apiKey.Last_Used_At, &apiKey.Revoked_At)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Scopes = strings.Split(scopes, ",")
+
+	return &apiKey, nil
+}
+
+func (r MySQLRepository) GetAPIKeys(ctx context.Context, tx store.Tx) ([]APIKeys, error) {
+	funcName := "repository.GetAPIKeys"
+
+	rows, err := store.SQL(tx).QueryContext(ctx, selectAPIKey+" ORDER BY id")
+	if err != nil {
+		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiKeys []APIKeys
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows.Scan)
+		if err != nil {
+			r.Log.Error(ctx, "get scan into get api keys with error", "error", err, "func_name", funcName)
+			return nil, err
+		}
+
+		apiKeys = append(apiKeys, *apiKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.Log.Error(ctx, "get rows err with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r MySQLRepository) GetAPIKeyById(ctx context.Context, tx store.Tx, apiKeyId uint32) (*APIKeys, error) {
+	funcName := "repository.GetAPIKeyById"
+
+	apiKey, err := scanAPIKey(store.SQL(tx).QueryRowContext(ctx, selectAPIKey+" WHERE id = ?", apiKeyId).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(apiKeyNotFoundError, apiKeyId), "func_name", funcName)
+			return nil, ErrAPIKeyNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get api key by id with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(apiKeyBaseError, apiKeyId, err)
+	}
+
+	return apiKey, nil
+}
+
+// GetAPIKeyByPrefix returns the API key with the prefix, or ErrAPIKeyNotFound
+func (r MySQLRepository) GetAPIKeyByPrefix(ctx context.Context, tx store.Tx, prefix string) (*APIKeys, error) {
+	funcName := "repository.GetAPIKeyByPrefix"
+
+	apiKey, err := scanAPIKey(store.SQL(tx).QueryRowContext(ctx, selectAPIKey+" WHERE prefix = ?", prefix).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get api key by prefix with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (r MySQLRepository) CreateAPIKey(ctx context.Context, tx store.Tx, apiKey *APIKeys) (*APIKeys, error) {
+	funcName := "repository.CreateAPIKey"
+
+	query := "INSERT INTO api_key(name, prefix, secret_hash, scopes, created_by) VALUES (?, ?, ?, ?, ?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, apiKey.Name, apiKey.Prefix, apiKey.Secret_Hash, strings.Join(apiKey.Scopes, ","), apiKey.Created_By)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create api key error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	apiKeyId, err := result.LastInsertId()
+	if err != nil {
+		r.Log.Error(ctx, "get result last insert id with create api key error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return r.GetAPIKeyById(ctx, tx, uint32(apiKeyId))
+}
+
+func (r MySQLRepository) RevokeAPIKey(ctx context.Context, tx store.Tx, apiKey *APIKeys) error {
+	query := "UPDATE api_key SET revoked_at = ? WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, apiKey.Revoked_At, apiKey.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with revoke api key error", "error", err, "func_name", "repository.RevokeAPIKey")
+		return err
+	}
+
+	return nil
+}
+
+func (r MySQLRepository) UpdateLastUsed(ctx context.Context, tx store.Tx, apiKey *APIKeys) error {
+	query := "UPDATE api_key SET last_used_at = ? WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, apiKey.Last_Used_At, apiKey.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with update last used error", "error", err, "func_name", "repository.UpdateLastUsed")
+		return err
+	}
+
+	return nil
+}
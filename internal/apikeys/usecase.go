@@ -0,0 +1,195 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Core business logic for API key operations
+
+const (
+	// keyPrefix starts every API key, followed by the key prefix and the
+	// secret separated by an underscore
+	keyPrefix = "tbk_"
+
+	// lastUsedInterval is how often the last use of a key is written
+	lastUsedInterval = time.Minute
+)
+
+type Usecase struct {
+	Repo     Repository
+	Tx       store.Beginner
+	Log      *logger.Logger
+	Validate *validator.Validate
+	// Policy tells which scopes the creator of a key may grant it
+	Policy auth.Policy
+}
+
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate, policy auth.Policy) Usecase {
+	return Usecase{
+		Repo:     repo,
+		Tx:       tx,
+		Log:      logger,
+		Validate: validate,
+		Policy:   policy,
+	}
+}
+
+// hashSecret returns the hex sha256 of a key secret, the form it is stored in
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (u *Usecase) GetAPIKeys(ctx context.Context) ([]APIKeys, error) {
+	funcName := "usecase.GetAPIKeys"
+
+	var apiKeys []APIKeys
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		apiKeys, err = u.Repo.GetAPIKeys(ctx, tx)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get api keys", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+// CreateAPIKey creates a key holding the scopes, returned with the key
+// itself, which is not stored. The creator, of claims, must hold every
+// scope it grants.
+func (u *Usecase) CreateAPIKey(ctx context.Context, claims auth.Claims, request *CreateAPIKeyRequest) (*CreatedAPIKeys, error) {
+	funcName := "usecase.CreateAPIKey"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to create api key", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+	for _, scope := range request.Scopes {
+		if !slices.Contains(auth.Permissions, auth.Permission(scope)) {
+			u.Log.Warn(ctx, "invalid request body to create api key: unknown scope", "scope", scope, "func_name", funcName)
+			return nil, errs.Validation(fmt.Sprintf("unknown scope %s", scope))
+		}
+		if !u.Policy.Allows(claims, auth.Permission(scope)) {
+			u.Log.Warn(ctx, "forbidden request body to create api key: scope not held", "scope", scope, "subject", claims.Subject, "func_name", funcName)
+			return nil, errs.Forbidden(fmt.Sprintf("scope %s is not held by the creator", scope))
+		}
+	}
+
+	prefix := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, errs.Internal(err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errs.Internal(err)
+	}
+	apiKey := &APIKeys{
+		Name:       request.Name,
+		Prefix:     hex.EncodeToString(prefix),
+		Scopes:     slices.Compact(slices.Sorted(slices.Values(request.Scopes))),
+		Created_By: request.Created_By,
+	}
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	apiKey.Secret_Hash = hashSecret(encodedSecret)
+
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) (err error) {
+		apiKey, err = u.Repo.CreateAPIKey(ctx, tx, apiKey)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to create api key", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return &CreatedAPIKeys{APIKeys: *apiKey, Key: keyPrefix + apiKey.Prefix + "_" + encodedSecret}, nil
+}
+
+// RevokeAPIKey stops the key from authenticating, keeping it listed
+func (u *Usecase) RevokeAPIKey(ctx context.Context, apiKeyId uint32) (*APIKeys, error) {
+	funcName := "usecase.RevokeAPIKey"
+
+	var apiKey *APIKeys
+	err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) (err error) {
+		apiKey, err = u.Repo.GetAPIKeyById(ctx, tx, apiKeyId)
+		if err != nil {
+			return err
+		}
+		if apiKey.Revoked_At != nil {
+			return nil
+		}
+
+		now := time.Now()
+		apiKey.Revoked_At = &now
+		return u.Repo.RevokeAPIKey(ctx, tx, apiKey)
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to revoke api key", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// VerifyKey returns the claims of an unrevoked key, a principal holding the
+// scopes of the key and no role. Unknown keys are ErrInvalidAPIKey, failing
+// reads internal errors, which are no failed authentication. The last use of
+// the key is written at most once per lastUsedInterval
+func (u *Usecase) VerifyKey(ctx context.Context, key string) (auth.Claims, error) {
+	funcName := "usecase.VerifyKey"
+
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(key, keyPrefix), "_")
+	if !found || !strings.HasPrefix(key, keyPrefix) {
+		return auth.Claims{}, ErrInvalidAPIKey
+	}
+
+	var apiKey *APIKeys
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		apiKey, err = u.Repo.GetAPIKeyByPrefix(ctx, tx, prefix)
+		return err
+	})
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		u.Log.Warn(ctx, "failed to verify api key: not found by prefix", "prefix", prefix, "func_name", funcName)
+		return auth.Claims{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		u.Log.Error(ctx, "failed to get api key by prefix", "error", err, "func_name", funcName)
+		return auth.Claims{}, errs.Internal(err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(apiKey.Secret_Hash)) != 1 || apiKey.Revoked_At != nil {
+		u.Log.Warn(ctx, "failed to verify api key", "prefix", prefix, "revoked", apiKey.Revoked_At != nil, "func_name", funcName)
+		return auth.Claims{}, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.Last_Used_At == nil || now.Sub(*apiKey.Last_Used_At) >= lastUsedInterval {
+		apiKey.Last_Used_At = &now
+		err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+			return u.Repo.UpdateLastUsed(ctx, tx, apiKey)
+		})
+		if err != nil {
+			u.Log.Warn(ctx, "failed to update api key last used", "error", err, "func_name", funcName)
+		}
+	}
+
+	return auth.Claims{Subject: "apikey:" + apiKey.Prefix, Scopes: apiKey.Scopes}, nil
+}
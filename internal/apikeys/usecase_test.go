@@ -0,0 +1,123 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func newMemoryUsecase(t *testing.T) *Usecase {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", nil)
+	db := memory.New()
+	CreateMemoryTables(db)
+
+	policy := auth.Policy{auth.RoleAdmin: auth.Permissions}
+	usecase := NewUsecase(NewMemoryRepository(log), db, log, validator.New(), policy)
+	return &usecase
+}
+
+// adminClaims are the claims of a principal holding every permission
+var adminClaims = auth.Claims{Subject: "admin", Role: auth.RoleAdmin}
+
+func TestUsecase_VerifyKey(t *testing.T) {
+	ctx := context.Background()
+	usecase := newMemoryUsecase(t)
+
+	created, err := usecase.CreateAPIKey(ctx, adminClaims, &CreateAPIKeyRequest{Name: "warehouse", Scopes: []string{"books:write", "authors:write", "books:write"}, Created_By: "admin"})
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+	if len(created.Scopes) != 2 || created.Secret_Hash == "" || created.Key == "" {
+		t.Fatalf("invalid created api key: got %+v", created)
+	}
+
+	claims, err := usecase.VerifyKey(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("failed to verify api key: %v", err)
+	}
+	if claims.Subject != "apikey:"+created.Prefix || claims.Role != "" || len(claims.Scopes) != 2 {
+		t.Fatalf("invalid claims: got %+v", claims)
+	}
+
+	apiKeys, err := usecase.GetAPIKeys(ctx)
+	if err != nil || len(apiKeys) != 1 || apiKeys[0].Last_Used_At == nil {
+		t.Fatalf("invalid api keys after use: got %+v %v", apiKeys, err)
+	}
+
+	for _, key := range []string{"", "tbk_", "tbk_" + created.Prefix + "_wrong", "tbk_unknown_secret", created.Key[len(keyPrefix):]} {
+		if _, err := usecase.VerifyKey(ctx, key); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Fatalf("invalid error of key %q: got %v, want %v", key, err, ErrInvalidAPIKey)
+		}
+	}
+
+	if _, err := usecase.RevokeAPIKey(ctx, created.ID); err != nil {
+		t.Fatalf("failed to revoke api key: %v", err)
+	}
+	if _, err := usecase.VerifyKey(ctx, created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("invalid error of a revoked key: got %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
+
+// failingRepository fails to read the keys, like a database down
+type failingRepository struct {
+	MemoryRepository
+}
+
+func (r failingRepository) GetAPIKeyByPrefix(ctx context.Context, tx store.Tx, prefix string) (*APIKeys, error) {
+	return nil, errors.New("database is down")
+}
+
+func TestUsecase_VerifyKey_failingRepository(t *testing.T) {
+	usecase := newMemoryUsecase(t)
+	usecase.Repo = failingRepository{usecase.Repo.(MemoryRepository)}
+
+	_, err := usecase.VerifyKey(context.Background(), "tbk_1234_secret")
+	if errors.Is(err, ErrInvalidAPIKey) || errs.KindOf(err) != errs.KindInternal {
+		t.Fatalf("invalid error of a failing repository: got %v, want an internal error", err)
+	}
+}
+
+func TestUsecase_CreateAPIKey(t *testing.T) {
+	usecase := newMemoryUsecase(t)
+
+	// a key allowed to create keys only, as VerifyKey returns it
+	keyClaims := auth.Claims{Subject: "apikey:0a1b2c3d", Scopes: []string{string(auth.APIKeysWrite)}}
+
+	testCases := []struct {
+		name     string
+		claims   auth.Claims
+		request  CreateAPIKeyRequest
+		wantKind errs.Kind
+	}{
+		{"unknown scope", adminClaims, CreateAPIKeyRequest{Name: "warehouse", Scopes: []string{"books:read"}}, errs.KindValidation},
+		{"no scope", adminClaims, CreateAPIKeyRequest{Name: "warehouse"}, errs.KindValidation},
+		{"short name", adminClaims, CreateAPIKeyRequest{Name: "wh", Scopes: []string{"books:write"}}, errs.KindValidation},
+		{"scope not held", keyClaims, CreateAPIKeyRequest{Name: "warehouse", Scopes: []string{string(auth.APIKeysWrite), string(auth.CountriesDelete)}}, errs.KindForbidden},
+		{"unknown role", auth.Claims{Subject: "guest", Role: "guest"}, CreateAPIKeyRequest{Name: "warehouse", Scopes: []string{"books:write"}}, errs.KindForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := usecase.CreateAPIKey(context.Background(), tc.claims, &tc.request)
+			if kind := errs.KindOf(err); kind != tc.wantKind {
+				t.Fatalf("invalid kind: got %s, want %s", kind, tc.wantKind)
+			}
+		})
+	}
+
+	created, err := usecase.CreateAPIKey(context.Background(), keyClaims, &CreateAPIKeyRequest{Name: "warehouse", Scopes: []string{string(auth.APIKeysWrite)}})
+	if err != nil {
+		t.Fatalf("failed to create api key with a held scope: %v", err)
+	}
+	if len(created.Scopes) != 1 {
+		t.Fatalf("invalid scopes: got %v", created.Scopes)
+	}
+}
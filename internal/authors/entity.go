@@ -3,6 +3,8 @@ package authors
 import (
 	"time"
 	"toko-buku-api/internal/countries"
+	"toko-buku-api/pkg/etag"
+	"toko-buku-api/pkg/query"
 )
 
 // Data models and structs specific to author functionality
@@ -16,6 +18,35 @@ type Authors struct {
 	City       string
 }
 
+// ETag returns the entity tag of the author row, leaving out the country,
+// which changes on its own
+func (a Authors) ETag() (string, error) {
+	type row Authors
+	r := row(a)
+	r.Country = nil
+	return etag.Of(r)
+}
+
+// QuerySpec lists the fields to paginate, sort and filter authors by
+var QuerySpec = query.Spec{
+	Columns: map[string]string{
+		"id":         "a.id",
+		"updated_at": "a.updated_at",
+		"country_id": "a.country_id",
+		"author":     "a.author",
+		"city":       "a.city",
+	},
+	Sortable: []string{"updated_at", "country_id", "author", "city"},
+	Key:      "id",
+	Types: map[string]query.Type{
+		"id":         query.TypeNumber,
+		"updated_at": query.TypeTime,
+		"country_id": query.TypeNumber,
+		"author":     query.TypeString,
+		"city":       query.TypeString,
+	},
+}
+
 // func (a Author) TableName() string {
 // 	return "authors"
 // }
@@ -31,4 +62,5 @@ type UpdateAuthorRequest struct {
 	Country_Id uint8  `json:"country_id"` // `validate:"required"`
 	Author     string `json:"author"`     // `validate:"required,min=3,max=50"`
 	City       string `json:"city"`       // `validate:"required,min=3,max=50"`
+	If_Match   string `json:"-"`
 }
@@ -0,0 +1,151 @@
+package authors
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko-buku-api/internal/countries"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for author data
+
+// MemoryTable is the in-memory table of authors, named after the migration
+const MemoryTable = "author"
+
+// CreateMemoryTables adds the author table to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, func(row any) map[string]uint64 {
+		return map[string]uint64{countries.MemoryTable: uint64(row.(Authors).Country_Id)}
+	})
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetAuthors(ctx context.Context, tx store.Tx, options *query.Options) (*[]Authors, *query.Page, error) {
+	funcName := "memory.GetAuthors"
+
+	authors, err := memory.Rows[Authors](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	for i := range authors {
+		if err := withCountry(tx, &authors[i]); err != nil {
+			r.Log.Error(ctx, "get author country with error", "error", err, "func_name", funcName)
+			return nil, nil, err
+		}
+	}
+
+	authors, page, err := query.Apply(options, authors, authorValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &authors, page, nil
+}
+
+func (r MemoryRepository) GetAuthorById(ctx context.Context, tx store.Tx, authorId uint16) (*Authors, error) {
+	funcName := "memory.GetAuthorById"
+
+	author, ok, err := memory.Get[Authors](memory.Of(tx), MemoryTable, uint64(authorId))
+	if err != nil {
+		r.Log.Error(ctx, "get row with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(authorBaseError, authorId, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf(authorNotFoundError, authorId, ErrAuthorNotFound)
+	}
+
+	if err := withCountry(tx, &author); err != nil {
+		r.Log.Error(ctx, "get author country with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(authorBaseError, authorId, err)
+	}
+
+	return &author, nil
+}
+
+// withCountry joins the country of the author like the LEFT JOIN in MySQL
+func withCountry(tx store.Tx, author *Authors) error {
+	country, _, err := memory.Get[countries.Countries](memory.Of(tx), countries.MemoryTable, uint64(author.Country_Id))
+	if err != nil {
+		return err
+	}
+
+	author.Country = &country
+	return nil
+}
+
+func (r MemoryRepository) CreateAuthor(ctx context.Context, tx store.Tx, author *Authors) (*Authors, error) {
+	author.Updated_At = time.Now()
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *author
+		created.ID = uint16(id)
+		created.Country = nil
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create author error", "error", err, "func_name", "memory.CreateAuthor")
+		return nil, err
+	}
+
+	author.ID = uint16(id)
+	return author, nil
+}
+
+func (r MemoryRepository) UpdateAuthor(ctx context.Context, tx store.Tx, author *Authors) (*Authors, error) {
+	if err := checkUnmodified(tx, author); err != nil {
+		r.Log.Warn(ctx, "author modified since it was read", "error", err, "func_name", "memory.UpdateAuthor")
+		return nil, err
+	}
+	author.Updated_At = time.Now()
+
+	updated := *author
+	updated.Country = nil
+	if err := memory.Of(tx).Put(MemoryTable, uint64(author.ID), updated); err != nil {
+		r.Log.Error(ctx, "put row with update author error", "error", err, "func_name", "memory.UpdateAuthor")
+		return nil, err
+	}
+
+	return author, nil
+}
+
+func (r MemoryRepository) DeleteAuthor(ctx context.Context, tx store.Tx, author *Authors) error {
+	if err := checkUnmodified(tx, author); err != nil {
+		r.Log.Warn(ctx, "author modified since it was read", "error", err, "func_name", "memory.DeleteAuthor")
+		return err
+	}
+	if err := memory.Of(tx).Delete(MemoryTable, uint64(author.ID)); err != nil {
+		r.Log.Error(ctx, "delete row with delete author error", "error", err, "func_name", "memory.DeleteAuthor")
+		return err
+	}
+
+	return nil
+}
+
+// checkUnmodified fails with ErrAuthorModified when the stored author has
+// another Updated_At than the one read, like the WHERE of MySQLRepository
+func checkUnmodified(tx store.Tx, author *Authors) error {
+	stored, ok, err := memory.Get[Authors](memory.Of(tx), MemoryTable, uint64(author.ID))
+	if err != nil {
+		return fmt.Errorf(authorBaseError, author.ID, err)
+	}
+	if !ok || !stored.Updated_At.Equal(author.Updated_At) {
+		return fmt.Errorf(authorModifiedError, author.ID, ErrAuthorModified)
+	}
+
+	return nil
+}
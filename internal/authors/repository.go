@@ -6,23 +6,55 @@ import (
 	"errors"
 	"fmt"
 	"toko-buku-api/internal/countries"
+	"toko-buku-api/pkg/errs"
 	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
 )
 
 // Database access methods for author data
 
-type Repository struct {
+// Repository is the author data access used by the usecase
+type Repository interface {
+	GetAuthors(ctx context.Context, tx store.Tx, options *query.Options) (*[]Authors, *query.Page, error)
+	GetAuthorById(ctx context.Context, tx store.Tx, authorId uint16) (*Authors, error)
+	CreateAuthor(ctx context.Context, tx store.Tx, author *Authors) (*Authors, error)
+	UpdateAuthor(ctx context.Context, tx store.Tx, author *Authors) (*Authors, error)
+	DeleteAuthor(ctx context.Context, tx store.Tx, author *Authors) error
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
 	DB  *sql.DB
 	Log *logger.Logger
 }
 
 const (
 	authorBaseError     = "author %d: %v"
-	authorNotFoundError = "author %d: not found"
+	authorNotFoundError = "author %d: %w"
+	authorModifiedError = "author %d: %w"
 )
 
-func NewRepository(db *sql.DB, logger *logger.Logger) Repository {
-	return Repository{
+var ErrAuthorNotFound = errs.NotFound("not found")
+
+// ErrAuthorModified is returned for writes to an author changed since it was
+// read, or whose ETag the client does not match
+var ErrAuthorModified = errs.PreconditionFailed("author was modified")
+
+const selectAuthors = `SELECT a.id, a.updated_at, a.country_id, a.author, a.city,
+	c.id, c.updated_at, c.iso3, c.country, c.nice_country, c.currency
+	FROM author a
+	JOIN country c ON a.country_id = c.id`
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "author", Columns: []string{"id", "updated_at", "country_id", "author", "city"}},
+	{Name: "country", Columns: []string{"id", "updated_at", "iso3", "country", "nice_country", "currency"}},
+}
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
 		DB:  db,
 		Log: logger,
 	}
@@ -32,15 +64,15 @@ func NewRepository(db *sql.DB, logger *logger.Logger) Repository {
 // FindAllComplete(..)
 // FindCompletePersonByID(..)
 
-func (r Repository) GetAuthors(ctx context.Context, tx *sql.Tx) (*[]Authors, error) {
+func (r MySQLRepository) GetAuthors(ctx context.Context, tx store.Tx, options *query.Options) (*[]Authors, *query.Page, error) {
 	var authors []Authors
 	var funcName = "repository.GetAuthors"
-	query := `SELECT a.*, c.* FROM authors a LEFT JOIN countries c ON a.country_id = c.id`
+	selectQuery, args := options.Select(selectAuthors, false)
 
-	rows, err := tx.QueryContext(ctx, query)
+	rows, err := store.SQL(tx).QueryContext(ctx, selectQuery, args...)
 	if err != nil {
 		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -48,7 +80,7 @@ func (r Repository) GetAuthors(ctx context.Context, tx *sql.Tx) (*[]Authors, err
 		author, err := scanIntoGetAuthors(rows)
 		if err != nil {
 			r.Log.Error(ctx, "get scan into get author with error", "error", err, "func_name", funcName)
-			return nil, err
+			return nil, nil, err
 		}
 
 		authors = append(authors, author)
@@ -56,15 +88,43 @@ func (r Repository) GetAuthors(ctx context.Context, tx *sql.Tx) (*[]Authors, err
 
 	if err := rows.Close(); err != nil {
 		r.Log.Error(ctx, "get rows close with error", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := rows.Err(); err != nil {
 		r.Log.Error(ctx, "get rows err with error", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, nil, err
+	}
+
+	var total int
+	countQuery, countArgs := options.Count(`SELECT COUNT(*) FROM author a`, false)
+	if err := store.SQL(tx).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.Log.Error(ctx, "get count authors with error", "error", err, "func_name", funcName)
+		return nil, nil, err
 	}
 
-	return &authors, nil
+	authors, page, err := query.Paginate(options, authors, total, authorValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &authors, page, nil
+}
+
+// authorValue returns the value of a QuerySpec field for cursors
+func authorValue(author Authors, field string) any {
+	switch field {
+	case "updated_at":
+		return author.Updated_At
+	case "country_id":
+		return author.Country_Id
+	case "author":
+		return author.Author
+	case "city":
+		return author.City
+	default:
+		return author.ID
+	}
 }
 
 func scanIntoGetAuthors(rows *sql.Rows) (selectedAuthor Authors, err error) {
@@ -86,7 +146,7 @@ func scanIntoGetAuthors(rows *sql.Rows) (selectedAuthor Authors, err error) {
 	// TODO
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return selectedAuthor, fmt.Errorf(authorNotFoundError, selectedAuthor.ID)
+			return selectedAuthor, fmt.Errorf(authorNotFoundError, selectedAuthor.ID, ErrAuthorNotFound)
 		}
 		return selectedAuthor, fmt.Errorf(authorBaseError, selectedAuthor.ID, err)
 	}
@@ -95,20 +155,20 @@ func scanIntoGetAuthors(rows *sql.Rows) (selectedAuthor Authors, err error) {
 	return
 }
 
-func (r Repository) GetAuthorById(ctx context.Context, tx *sql.Tx, authorId uint16) (*Authors, error) {
+func (r MySQLRepository) GetAuthorById(ctx context.Context, tx store.Tx, authorId uint16) (*Authors, error) {
 	funcName := "repository.GetAuthorById"
-	query := `SELECT a.*, c.* FROM authors a LEFT JOIN countries c ON a.country_id = c.id WHERE a.id = ?`
+	query := selectAuthors + ` WHERE a.id = ?`
 
-	row := tx.QueryRowContext(ctx, query, authorId)
+	row := store.SQL(tx).QueryRowContext(ctx, query, authorId)
 
 	author, err := scanRowIntoGetAuthorById(row, authorId)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			r.Log.Error(ctx, "get scan row into get author by id with errors.Is", "error", err, "func_name", funcName)
-			return nil, errors.New("not found")
+		if errors.Is(err, ErrAuthorNotFound) {
+			r.Log.Warn(ctx, "get scan row into get author by id with not found", "error", err, "func_name", funcName)
+			return nil, err
 		}
 		r.Log.Error(ctx, "get scan row into get author by id with error", "error", err, "func_name", funcName)
-		return nil, errors.New("not found")
+		return nil, err
 	}
 
 	return author, nil
@@ -132,7 +192,7 @@ func scanRowIntoGetAuthorById(row *sql.Row, authorId uint16) (*Authors, error) {
 		&country.Currency,
 	); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf(authorNotFoundError, authorId)
+			return nil, fmt.Errorf(authorNotFoundError, authorId, ErrAuthorNotFound)
 		}
 		return nil, fmt.Errorf(authorBaseError, authorId, err)
 	}
@@ -141,11 +201,11 @@ func scanRowIntoGetAuthorById(row *sql.Row, authorId uint16) (*Authors, error) {
 	return &author, nil
 }
 
-func (r Repository) CreateAuthor(ctx context.Context, tx *sql.Tx, author *Authors) (auther *Authors, err error) {
+func (r MySQLRepository) CreateAuthor(ctx context.Context, tx store.Tx, author *Authors) (auther *Authors, err error) {
 	funcName := "repository.CreateAuthor"
 
-	query := "INSERT INTO authors(country_id, author, city) VALUES (?, ?, ?)"
-	result, err := tx.ExecContext(ctx, query, author.Country_Id, author.Author, author.City)
+	query := "INSERT INTO author(country_id, author, city) VALUES (?, ?, ?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, author.Country_Id, author.Author, author.City)
 	if err != nil {
 		r.Log.Error(ctx, "get exec context with create author error", "error", err, "func_name", funcName)
 		return nil, err
@@ -160,24 +220,52 @@ func (r Repository) CreateAuthor(ctx context.Context, tx *sql.Tx, author *Author
 	return author, nil
 }
 
-func (r Repository) UpdateAuthor(ctx context.Context, tx *sql.Tx, author *Authors) (*Authors, error) {
-	query := "UPDATE authors SET author = ?, city = ? WHERE id = ?"
-	_, err := tx.ExecContext(ctx, query, author.Author, author.City, author.ID)
+// UpdateAuthor writes the author read with its Updated_At, failing with
+// ErrAuthorModified when the row changed since
+func (r MySQLRepository) UpdateAuthor(ctx context.Context, tx store.Tx, author *Authors) (*Authors, error) {
+	funcName := "repository.UpdateAuthor"
+
+	query := "UPDATE author SET country_id = ?, author = ?, city = ?, updated_at = CURRENT_TIMESTAMP(6) WHERE id = ? AND updated_at = ?"
+	result, err := store.SQL(tx).ExecContext(ctx, query, author.Country_Id, author.Author, author.City, author.ID, author.Updated_At)
 	if err != nil {
-		r.Log.Error(ctx, "get exec context with update author error", "error", err, "func_name", "repository.UpdateAuthor")
+		r.Log.Error(ctx, "get exec context with update author error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	if err := r.checkUnmodified(ctx, result, author.ID, funcName); err != nil {
 		return nil, err
 	}
 
 	return author, nil
 }
 
-func (r Repository) DeleteAuthor(ctx context.Context, tx *sql.Tx, author *Authors) error {
-	query := "DELETE FROM authors WHERE id = ?"
-	_, err := tx.ExecContext(ctx, query, author.ID)
+// DeleteAuthor deletes the author read with its Updated_At, failing with
+// ErrAuthorModified when the row changed since
+func (r MySQLRepository) DeleteAuthor(ctx context.Context, tx store.Tx, author *Authors) error {
+	funcName := "repository.DeleteAuthor"
+
+	query := "DELETE FROM author WHERE id = ? AND updated_at = ?"
+	result, err := store.SQL(tx).ExecContext(ctx, query, author.ID, author.Updated_At)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with delete author error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return r.checkUnmodified(ctx, result, author.ID, funcName)
+}
+
+// checkUnmodified fails with ErrAuthorModified when the write matched no
+// row, its updated_at having changed
+func (r MySQLRepository) checkUnmodified(ctx context.Context, result sql.Result, authorId uint16, funcName string) error {
+	affected, err := result.RowsAffected()
 	if err != nil {
-		r.Log.Error(ctx, "get exec context with delete author error", "error", err, "func_name", "repository.DeleteAuthor")
+		r.Log.Error(ctx, "get result rows affected with error", "error", err, "func_name", funcName)
 		return err
 	}
+	if affected == 0 {
+		r.Log.Warn(ctx, "author modified since it was read", "author_id", authorId, "func_name", funcName)
+		return fmt.Errorf(authorModifiedError, authorId, ErrAuthorModified)
+	}
 
 	return nil
 }
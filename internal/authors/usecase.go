@@ -2,8 +2,12 @@ package authors
 
 import (
 	"context"
+	"fmt"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/etag"
 	"toko-buku-api/pkg/logger"
-	"toko-buku-api/utils"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -12,48 +16,45 @@ import (
 
 type Usecase struct {
 	Repo     Repository
+	Tx       store.Beginner
 	Log      *logger.Logger
 	Validate *validator.Validate
 }
 
-func NewUsecase(repo Repository, logger *logger.Logger, validate *validator.Validate) Usecase {
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate) Usecase {
 	return Usecase{
 		Repo:     repo,
+		Tx:       tx,
 		Log:      logger,
 		Validate: validate,
 	}
 }
 
-func (u *Usecase) GetAuthors(ctx context.Context) (*[]Authors, error) {
+func (u *Usecase) GetAuthors(ctx context.Context, options *query.Options) (*[]Authors, *query.Page, error) {
 	funcName := "usecase.GetAuthors"
 
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to get authors: repo db begin", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
-
-	authors, err := u.Repo.GetAuthors(ctx, tx)
+	var authors *[]Authors
+	var page *query.Page
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		authors, page, err = u.Repo.GetAuthors(ctx, tx, options)
+		return err
+	})
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to get authors: not found", "error", err, "func_name", funcName)
-		return nil, err
+		u.Log.Warn(ctx, "failed request body to get authors", "error", err, "func_name", funcName)
+		return nil, nil, err
 	}
 
-	return authors, nil
+	return authors, page, nil
 }
 
 func (u *Usecase) GetAuthorById(ctx context.Context, authorId uint16) (*Authors, error) {
 	funcName := "usecase.GetAuthorById"
 
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to get author by id: repo db begin", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
-
-	author, err := u.Repo.GetAuthorById(ctx, tx, authorId)
+	var author *Authors
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		author, err = u.Repo.GetAuthorById(ctx, tx, authorId)
+		return err
+	})
 	if err != nil {
 		u.Log.Warn(ctx, "failed request body to get author by id: not found by id", "error", err, "func_name", funcName)
 		return nil, err
@@ -68,23 +69,18 @@ func (u *Usecase) CreateAuthor(ctx context.Context, request *CreateAuthorRequest
 	err := u.Validate.Struct(request)
 	if err != nil {
 		u.Log.Warn(ctx, "invalid request body to create author", "error", err, "func_name", funcName)
-		return nil, err
-	}
-
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to create author: repo db begin", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
-
-	createdAuthor := &Authors{
-		Country_Id: request.Country_Id,
-		Author:     request.Author,
-		City:       request.City,
+		return nil, errs.Invalid(err)
 	}
 
-	createdAuthor, err = u.Repo.CreateAuthor(ctx, tx, createdAuthor)
+	var createdAuthor *Authors
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) (err error) {
+		createdAuthor, err = u.Repo.CreateAuthor(ctx, tx, &Authors{
+			Country_Id: request.Country_Id,
+			Author:     request.Author,
+			City:       request.City,
+		})
+		return err
+	})
 	if err != nil {
 		u.Log.Warn(ctx, "invalid request body to create author", "error", err, "func_name", funcName)
 		return nil, err
@@ -99,35 +95,41 @@ func (u *Usecase) UpdateAuthor(ctx context.Context, request *UpdateAuthorRequest
 	err := u.Validate.Struct(request)
 	if err != nil {
 		u.Log.Warn(ctx, "invalid request body to update author", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, errs.Invalid(err)
 	}
 
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to update: repo db begin", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
+	var updatedAuthor *Authors
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		oldAuthor, err := u.Repo.GetAuthorById(ctx, tx, uint16(request.ID))
+		if err != nil {
+			u.Log.Warn(ctx, "failed request body to update author: repo GetAuthorById", "error", err, "func_name", funcName)
+			return err
+		}
 
-	oldAuthor, err := u.Repo.GetAuthorById(ctx, tx, uint16(request.ID))
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to update author: repo GetAuthorById", "error", err, "func_name", funcName)
-		return nil, err
-	}
+		if err := checkETag(oldAuthor, request.If_Match); err != nil {
+			return err
+		}
 
-	if request.Author != "" {
-		oldAuthor.Author = request.Author
-	}
+		if request.Author != "" {
+			oldAuthor.Author = request.Author
+		}
 
-	if request.City != "" {
-		oldAuthor.City = request.City
-	}
+		if request.City != "" {
+			oldAuthor.City = request.City
+		}
 
-	if request.Country_Id > 0 {
-		oldAuthor.Country_Id = request.Country_Id
-	}
+		if request.Country_Id > 0 {
+			oldAuthor.Country_Id = request.Country_Id
+		}
+
+		if _, err := u.Repo.UpdateAuthor(ctx, tx, oldAuthor); err != nil {
+			return err
+		}
 
-	updatedAuthor, err := u.Repo.UpdateAuthor(ctx, tx, oldAuthor)
+		// read it back for its new updated_at, and its new country
+		updatedAuthor, err = u.Repo.GetAuthorById(ctx, tx, oldAuthor.ID)
+		return err
+	})
 	if err != nil {
 		u.Log.Warn(ctx, "failed request body to update author", "error", err, "func_name", funcName)
 		return nil, err
@@ -136,21 +138,45 @@ func (u *Usecase) UpdateAuthor(ctx context.Context, request *UpdateAuthorRequest
 	return updatedAuthor, nil
 }
 
-func (u *Usecase) DeleteAuthor(ctx context.Context, authorId uint16) error {
+// DeleteAuthor deletes the author, when its ETag matches ifMatch unless
+// ifMatch is empty
+func (u *Usecase) DeleteAuthor(ctx context.Context, authorId uint16, ifMatch string) error {
 	funcName := "usecase.DeleteAuthor"
 
-	tx, err := u.Repo.DB.Begin()
+	err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		author, err := u.Repo.GetAuthorById(ctx, tx, authorId)
+		if err != nil {
+			return err
+		}
+
+		if err := checkETag(author, ifMatch); err != nil {
+			return err
+		}
+
+		return u.Repo.DeleteAuthor(ctx, tx, author)
+	})
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to delete author: repo db begin", "error", err, "func_name", funcName)
+		u.Log.Warn(ctx, "failed request body to delete author", "error", err, "func_name", funcName)
 		return err
 	}
-	defer utils.CommitOrRollback(tx)
 
-	author, err := u.Repo.GetAuthorById(ctx, tx, authorId)
+	return nil
+}
+
+// checkETag fails with ErrAuthorModified when ifMatch, an If-Match header,
+// is set and does not match the ETag of the author
+func checkETag(author *Authors, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
+	}
+
+	tag, err := etag.Of(author)
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to delete author", "error", err, "func_name", funcName)
 		return err
 	}
+	if !etag.Match(ifMatch, tag, false) {
+		return fmt.Errorf(authorModifiedError, author.ID, ErrAuthorModified)
+	}
 
-	return u.Repo.DeleteAuthor(ctx, tx, author)
+	return nil
 }
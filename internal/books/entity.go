@@ -0,0 +1,72 @@
+package books
+
+import (
+	"time"
+	"toko-buku-api/internal/authors"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/pkg/query"
+)
+
+// Data models and structs specific to book functionality
+
+type Books struct {
+	ID         uint32
+	Created_At time.Time
+	Updated_At *time.Time
+	Deleted_At *time.Time `json:"-"`
+	Author_Id  uint16
+	Author     *authors.Authors `json:",omitempty"`
+	Type_Id    *uint16
+	Type       *types.Types `json:",omitempty"`
+	Title      string
+	Sku        string
+	Price      float64
+	Stock      uint32
+}
+
+// QuerySpec lists the fields to paginate, sort and filter books by
+var QuerySpec = query.Spec{
+	Columns: map[string]string{
+		"id":         "b.id",
+		"created_at": "b.created_at",
+		"updated_at": "b.updated_at",
+		"author_id":  "b.author_id",
+		"author":     "a.author",
+		"type_id":    "b.type_id",
+		"type":       "t.type",
+		"title":      "b.title",
+		"sku":        "b.sku",
+		"price":      "b.price",
+		"stock":      "b.stock",
+	},
+	Sortable: []string{"created_at", "author_id", "title", "sku", "price", "stock"},
+	Key:      "id",
+	Types: map[string]query.Type{
+		"id":         query.TypeNumber,
+		"created_at": query.TypeTime,
+		"author_id":  query.TypeNumber,
+		"title":      query.TypeString,
+		"sku":        query.TypeString,
+		"price":      query.TypeNumber,
+		"stock":      query.TypeNumber,
+	},
+}
+
+type CreateBookRequest struct {
+	Author_Id uint16  `validate:"required" json:"author_id"`
+	Type_Id   *uint16 `validate:"omitempty,gt=0" json:"type_id"`
+	Title     string  `validate:"required,min=1,max=50" json:"title"`
+	Sku       string  `validate:"required,min=3,max=30,sku" json:"sku"`
+	Price     float64 `validate:"gt=0,lte=9999.99" json:"price"`
+	Stock     uint32  `validate:"lte=16777215" json:"stock"`
+}
+
+type UpdateBookRequest struct {
+	ID        uint32   `json:"id"`
+	Author_Id uint16   `json:"author_id"`
+	Type_Id   *uint16  `validate:"omitempty,gt=0" json:"type_id"`
+	Title     string   `validate:"omitempty,min=1,max=50" json:"title"`
+	Sku       string   `validate:"omitempty,min=3,max=30,sku" json:"sku"`
+	Price     *float64 `validate:"omitempty,gt=0,lte=9999.99" json:"price"`
+	Stock     *uint32  `validate:"omitempty,lte=16777215" json:"stock"`
+}
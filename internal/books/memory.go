@@ -0,0 +1,183 @@
+package books
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko-buku-api/internal/authors"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for book data
+
+// MemoryTable is the in-memory table of books, named after the migration
+const MemoryTable = "book"
+
+// CreateMemoryTables adds the book table to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, func(row any) map[string]uint64 {
+		book := row.(Books)
+
+		refs := map[string]uint64{authors.MemoryTable: uint64(book.Author_Id)}
+		if book.Type_Id != nil {
+			refs[types.MemoryTable] = uint64(*book.Type_Id)
+		}
+
+		return refs
+	})
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetBooks(ctx context.Context, tx store.Tx, options *query.Options) ([]Books, *query.Page, error) {
+	books, err := r.getBooks(tx)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetBooks")
+		return nil, nil, err
+	}
+
+	return query.Apply(options, books, bookValue)
+}
+
+func (r MemoryRepository) GetBookById(ctx context.Context, tx store.Tx, bookId uint32) (*Books, error) {
+	funcName := "memory.GetBookById"
+
+	book, ok, err := memory.Get[Books](memory.Of(tx), MemoryTable, uint64(bookId))
+	if err != nil {
+		r.Log.Error(ctx, "get row with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(bookBaseError, bookId, err)
+	}
+	if !ok || book.Deleted_At != nil {
+		r.Log.Warn(ctx, fmt.Sprintf(bookNotFoundError, bookId), "func_name", funcName)
+		return nil, ErrBookNotFound
+	}
+
+	if err := withAuthorAndType(tx, &book); err != nil {
+		r.Log.Error(ctx, "get book author and type with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(bookBaseError, bookId, err)
+	}
+
+	return &book, nil
+}
+
+// GetBookBySku returns the non-deleted book owning the sku, or ErrBookNotFound
+func (r MemoryRepository) GetBookBySku(ctx context.Context, tx store.Tx, sku string) (*Books, error) {
+	books, err := r.getBooks(tx)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetBookBySku")
+		return nil, err
+	}
+
+	for _, book := range books {
+		if book.Sku == sku {
+			return &book, nil
+		}
+	}
+
+	return nil, ErrBookNotFound
+}
+
+// getBooks returns the non-deleted books joined with their author and type
+func (r MemoryRepository) getBooks(tx store.Tx) ([]Books, error) {
+	rows, err := memory.Rows[Books](memory.Of(tx), MemoryTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var books []Books
+	for _, book := range rows {
+		if book.Deleted_At != nil {
+			continue
+		}
+
+		if err := withAuthorAndType(tx, &book); err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+
+	return books, nil
+}
+
+// withAuthorAndType joins the author and the optional type of the book
+func withAuthorAndType(tx store.Tx, book *Books) error {
+	author, _, err := memory.Get[authors.Authors](memory.Of(tx), authors.MemoryTable, uint64(book.Author_Id))
+	if err != nil {
+		return err
+	}
+	book.Author = &author
+
+	if book.Type_Id == nil {
+		return nil
+	}
+
+	bookType, ok, err := memory.Get[types.Types](memory.Of(tx), types.MemoryTable, uint64(*book.Type_Id))
+	if err != nil {
+		return err
+	}
+	if ok {
+		book.Type = &bookType
+	}
+
+	return nil
+}
+
+func (r MemoryRepository) CreateBook(ctx context.Context, tx store.Tx, book *Books) (*Books, error) {
+	book.Created_At = time.Now()
+
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *book
+		created.ID = uint32(id)
+		created.Author, created.Type = nil, nil
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create book error", "error", err, "func_name", "memory.CreateBook")
+		return nil, err
+	}
+
+	book.ID = uint32(id)
+	return book, nil
+}
+
+func (r MemoryRepository) UpdateBook(ctx context.Context, tx store.Tx, book *Books) (*Books, error) {
+	updatedAt := time.Now()
+	book.Updated_At = &updatedAt
+
+	updated := *book
+	updated.Author, updated.Type = nil, nil
+	if err := memory.Of(tx).Put(MemoryTable, uint64(book.ID), updated); err != nil {
+		r.Log.Error(ctx, "put row with update book error", "error", err, "func_name", "memory.UpdateBook")
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// DeleteBook soft deletes the book by setting deleted_at
+func (r MemoryRepository) DeleteBook(ctx context.Context, tx store.Tx, book *Books) error {
+	deletedAt := time.Now()
+
+	deleted := *book
+	deleted.Author, deleted.Type = nil, nil
+	deleted.Deleted_At = &deletedAt
+	if err := memory.Of(tx).Put(MemoryTable, uint64(book.ID), deleted); err != nil {
+		r.Log.Error(ctx, "put row with delete book error", "error", err, "func_name", "memory.DeleteBook")
+		return err
+	}
+
+	return nil
+}
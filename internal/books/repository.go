@@ -0,0 +1,269 @@
+package books
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"toko-buku-api/internal/authors"
+	"toko-buku-api/internal/types"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
+)
+
+// Database access methods for book data
+
+// Repository is the book data access used by the usecase
+type Repository interface {
+	GetBooks(ctx context.Context, tx store.Tx, options *query.Options) ([]Books, *query.Page, error)
+	GetBookById(ctx context.Context, tx store.Tx, bookId uint32) (*Books, error)
+	GetBookBySku(ctx context.Context, tx store.Tx, sku string) (*Books, error)
+	CreateBook(ctx context.Context, tx store.Tx, book *Books) (*Books, error)
+	UpdateBook(ctx context.Context, tx store.Tx, book *Books) (*Books, error)
+	DeleteBook(ctx context.Context, tx store.Tx, book *Books) error
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
+	DB  *sql.DB
+	Log *logger.Logger
+}
+
+const (
+	bookBaseError     = "book %d: %v"
+	bookNotFoundError = "book %d: not found"
+)
+
+var (
+	ErrBookNotFound = errs.NotFound("book not found")
+	ErrSkuConflict  = errs.Conflict("sku already exists")
+)
+
+const selectBooks = `SELECT b.id, b.created_at, b.updated_at, b.author_id, b.type_id, b.title, b.sku, b.price, b.stock,
+	a.id, a.updated_at, a.country_id, a.author, a.city,
+	t.id, t.updated_at, t.type
+	FROM book b
+	JOIN author a ON b.author_id = a.id
+	LEFT JOIN ` + "`type`" + ` t ON b.type_id = t.id
+	WHERE b.deleted_at IS NULL`
+
+const countBooks = `SELECT COUNT(*)
+	FROM book b
+	JOIN author a ON b.author_id = a.id
+	LEFT JOIN ` + "`type`" + ` t ON b.type_id = t.id
+	WHERE b.deleted_at IS NULL`
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "book", Columns: []string{"id", "created_at", "updated_at", "deleted_at", "author_id", "type_id", "title", "sku", "price", "stock"}},
+	{Name: "author", Columns: []string{"id", "updated_at", "country_id", "author", "city"}},
+	{Name: "type", Columns: []string{"id", "updated_at", "type"}},
+}
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+func (r MySQLRepository) GetBooks(ctx context.Context, tx store.Tx, options *query.Options) ([]Books, *query.Page, error) {
+	var funcName = "repository.GetBooks"
+	var books []Books
+	selectQuery, args := options.Select(selectBooks, true)
+
+	rows, err := store.SQL(tx).QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		book, err := scanIntoBook(rows)
+		if err != nil {
+			r.Log.Error(ctx, "get scan into get books with error", "error", err, "func_name", funcName)
+			return nil, nil, err
+		}
+
+		books = append(books, *book)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.Log.Error(ctx, "get rows err with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	var total int
+	countQuery, countArgs := options.Count(countBooks, true)
+	if err := store.SQL(tx).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.Log.Error(ctx, "get count books with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	return query.Paginate(options, books, total, bookValue)
+}
+
+// bookValue returns the value of a QuerySpec field for cursors
+func bookValue(book Books, field string) any {
+	switch field {
+	case "created_at":
+		return book.Created_At
+	case "updated_at":
+		return book.Updated_At
+	case "author_id":
+		return book.Author_Id
+	case "author":
+		if book.Author == nil {
+			return nil
+		}
+		return book.Author.Author
+	case "type_id":
+		return book.Type_Id
+	case "type":
+		if book.Type == nil {
+			return nil
+		}
+		return book.Type.Type
+	case "title":
+		return book.Title
+	case "sku":
+		return book.Sku
+	case "price":
+		return book.Price
+	case "stock":
+		return book.Stock
+	default:
+		return book.ID
+	}
+}
+
+func (r MySQLRepository) GetBookById(ctx context.Context, tx store.Tx, bookId uint32) (*Books, error) {
+	funcName := "repository.GetBookById"
+	query := selectBooks + ` AND b.id = ?`
+
+	row := store.SQL(tx).QueryRowContext(ctx, query, bookId)
+
+	book, err := scanIntoBook(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(bookNotFoundError, bookId), "func_name", funcName)
+			return nil, ErrBookNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get book by id with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(bookBaseError, bookId, err)
+	}
+
+	return book, nil
+}
+
+// GetBookBySku returns the non-deleted book owning the sku, or ErrBookNotFound
+func (r MySQLRepository) GetBookBySku(ctx context.Context, tx store.Tx, sku string) (*Books, error) {
+	funcName := "repository.GetBookBySku"
+	query := selectBooks + ` AND b.sku = ?`
+
+	row := store.SQL(tx).QueryRowContext(ctx, query, sku)
+
+	book, err := scanIntoBook(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get book by sku with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return book, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIntoBook(row scanner) (*Books, error) {
+	var book = Books{}
+	var author = authors.Authors{}
+	var typeId sql.NullInt32
+	var typeUpdatedAt sql.NullTime
+	var typeName sql.NullString
+
+	if err := row.Scan(
+		&book.ID,
+		&book.Created_At,
+		&book.Updated_At,
+		&book.Author_Id,
+		&book.Type_Id,
+		&book.Title,
+		&book.Sku,
+		&book.Price,
+		&book.Stock,
+		&author.ID,
+		&author.Updated_At,
+		&author.Country_Id,
+		&author.Author,
+		&author.City,
+		&typeId,
+		&typeUpdatedAt,
+		&typeName,
+	); err != nil {
+		return nil, err
+	}
+
+	book.Author = &author
+	if typeId.Valid {
+		book.Type = &types.Types{
+			ID:   uint16(typeId.Int32),
+			Type: typeName.String,
+		}
+		if typeUpdatedAt.Valid {
+			book.Type.Updated_At = &typeUpdatedAt.Time
+		}
+	}
+
+	return &book, nil
+}
+
+func (r MySQLRepository) CreateBook(ctx context.Context, tx store.Tx, book *Books) (*Books, error) {
+	funcName := "repository.CreateBook"
+
+	query := "INSERT INTO book(author_id, type_id, title, sku, price, stock) VALUES (?, ?, ?, ?, ?, ?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, book.Author_Id, book.Type_Id, book.Title, book.Sku, book.Price, book.Stock)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create book error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	bookId, err := result.LastInsertId()
+	if err != nil {
+		r.Log.Error(ctx, "get result last insert id with create book error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	book.ID = uint32(bookId)
+	return book, nil
+}
+
+func (r MySQLRepository) UpdateBook(ctx context.Context, tx store.Tx, book *Books) (*Books, error) {
+	query := "UPDATE book SET author_id = ?, type_id = ?, title = ?, sku = ?, price = ?, stock = ? WHERE id = ? AND deleted_at IS NULL"
+	_, err := store.SQL(tx).ExecContext(ctx, query, book.Author_Id, book.Type_Id, book.Title, book.Sku, book.Price, book.Stock, book.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with update book error", "error", err, "func_name", "repository.UpdateBook")
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// DeleteBook soft deletes the book by setting deleted_at
+func (r MySQLRepository) DeleteBook(ctx context.Context, tx store.Tx, book *Books) error {
+	query := "UPDATE book SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL"
+	_, err := store.SQL(tx).ExecContext(ctx, query, book.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with delete book error", "error", err, "func_name", "repository.DeleteBook")
+		return err
+	}
+
+	return nil
+}
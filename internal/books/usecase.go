@@ -0,0 +1,189 @@
+package books
+
+import (
+	"context"
+	"errors"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Core business logic for book operations
+
+type Usecase struct {
+	Repo     Repository
+	Tx       store.Beginner
+	Log      *logger.Logger
+	Validate *validator.Validate
+}
+
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate) Usecase {
+	return Usecase{
+		Repo:     repo,
+		Tx:       tx,
+		Log:      logger,
+		Validate: validate,
+	}
+}
+
+func (u *Usecase) GetBooks(ctx context.Context, options *query.Options) ([]Books, *query.Page, error) {
+	funcName := "usecase.GetBooks"
+
+	var books []Books
+	var page *query.Page
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		books, page, err = u.Repo.GetBooks(ctx, tx, options)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get books", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	return books, page, nil
+}
+
+func (u *Usecase) GetBookById(ctx context.Context, bookId uint32) (*Books, error) {
+	funcName := "usecase.GetBookById"
+
+	var book *Books
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		book, err = u.Repo.GetBookById(ctx, tx, bookId)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get book by id: not found by id", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return book, nil
+}
+
+func (u *Usecase) CreateBook(ctx context.Context, request *CreateBookRequest) (*Books, error) {
+	funcName := "usecase.CreateBook"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to create book", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+
+	var createdBook *Books
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		_, err := u.Repo.GetBookBySku(ctx, tx, request.Sku)
+		if err == nil {
+			u.Log.Warn(ctx, "invalid request body to create book: sku already exists", "sku", request.Sku, "func_name", funcName)
+			return ErrSkuConflict
+		}
+		if !errors.Is(err, ErrBookNotFound) {
+			return err
+		}
+
+		book, err := u.Repo.CreateBook(ctx, tx, &Books{
+			Author_Id: request.Author_Id,
+			Type_Id:   request.Type_Id,
+			Title:     request.Title,
+			Sku:       request.Sku,
+			Price:     request.Price,
+			Stock:     request.Stock,
+		})
+		if err != nil {
+			return err
+		}
+
+		createdBook, err = u.Repo.GetBookById(ctx, tx, book.ID)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to create book", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return createdBook, nil
+}
+
+func (u *Usecase) UpdateBook(ctx context.Context, request *UpdateBookRequest) (*Books, error) {
+	funcName := "usecase.UpdateBook"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to update book", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+
+	var updatedBook *Books
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		oldBook, err := u.Repo.GetBookById(ctx, tx, request.ID)
+		if err != nil {
+			u.Log.Warn(ctx, "failed request body to update book: repo GetBookById", "error", err, "func_name", funcName)
+			return err
+		}
+
+		if request.Sku != "" && request.Sku != oldBook.Sku {
+			_, err = u.Repo.GetBookBySku(ctx, tx, request.Sku)
+			if err == nil {
+				u.Log.Warn(ctx, "invalid request body to update book: sku already exists", "sku", request.Sku, "func_name", funcName)
+				return ErrSkuConflict
+			}
+			if !errors.Is(err, ErrBookNotFound) {
+				return err
+			}
+			oldBook.Sku = request.Sku
+		}
+
+		if request.Author_Id > 0 {
+			oldBook.Author_Id = request.Author_Id
+		}
+
+		if request.Type_Id != nil {
+			oldBook.Type_Id = request.Type_Id
+		}
+
+		if request.Title != "" {
+			oldBook.Title = request.Title
+		}
+
+		if request.Price != nil {
+			oldBook.Price = *request.Price
+		}
+
+		if request.Stock != nil {
+			oldBook.Stock = *request.Stock
+		}
+
+		if _, err = u.Repo.UpdateBook(ctx, tx, oldBook); err != nil {
+			return err
+		}
+
+		updatedBook, err = u.Repo.GetBookById(ctx, tx, oldBook.ID)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to update book", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return updatedBook, nil
+}
+
+func (u *Usecase) DeleteBook(ctx context.Context, bookId uint32) error {
+	funcName := "usecase.DeleteBook"
+
+	err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		book, err := u.Repo.GetBookById(ctx, tx, bookId)
+		if err != nil {
+			return err
+		}
+
+		return u.Repo.DeleteBook(ctx, tx, book)
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to delete book", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package books
+
+import (
+	"regexp"
+	"toko-buku-api/pkg/validation"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Custom validation rules for book requests
+
+// skuPattern matches SKUs like `bumi-manusia_1` or `novels-name-2`
+var skuPattern = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// RegisterValidations registers the `sku` tag used by the book requests,
+// with its messages
+func RegisterValidations(validate *validator.Validate) error {
+	err := validate.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+		return skuPattern.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		return err
+	}
+
+	return validation.RegisterTranslation(validate, "sku", map[string]string{
+		validation.LanguageEnglish:    "{0} must be lowercase letters and numbers joined by - or _",
+		validation.LanguageIndonesian: "{0} harus berupa huruf kecil dan angka yang digabung dengan - atau _",
+	})
+}
@@ -0,0 +1,36 @@
+package books
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestRegisterValidations_sku(t *testing.T) {
+	validate := validator.New()
+	if err := RegisterValidations(validate); err != nil {
+		t.Fatalf("failed to register validations: %v", err)
+	}
+
+	testCases := []struct {
+		sku   string
+		valid bool
+	}{
+		{sku: "kapal-van-der_1", valid: true},
+		{sku: "bumi-manusia_1", valid: true},
+		{sku: "novels-name-2", valid: true},
+		{sku: "Bumi-Manusia", valid: false},
+		{sku: "bumi manusia", valid: false},
+		{sku: "-bumi", valid: false},
+		{sku: "bumi--manusia", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sku, func(t *testing.T) {
+			err := validate.Var(tc.sku, "sku")
+			if (err == nil) != tc.valid {
+				t.Fatalf("invalid sku validation for %q: got %v, want valid=%v", tc.sku, err, tc.valid)
+			}
+		})
+	}
+}
@@ -1,16 +1,40 @@
 package countries
 
-import "time"
+import (
+	"time"
+	"toko-buku-api/pkg/query"
+)
 
 type Countries struct {
 	ID           uint8
-	Updated_At   time.Time
+	Updated_At   *time.Time
 	Iso3         string
 	Country      string
 	Nice_Country string
 	Currency     string
 }
 
+// QuerySpec lists the fields to paginate, sort and filter countries by
+var QuerySpec = query.Spec{
+	Columns: map[string]string{
+		"id":           "id",
+		"updated_at":   "updated_at",
+		"iso3":         "iso3",
+		"country":      "country",
+		"nice_country": "nice_country",
+		"currency":     "currency",
+	},
+	Sortable: []string{"iso3", "country", "nice_country", "currency"},
+	Key:      "id",
+	Types: map[string]query.Type{
+		"id":           query.TypeNumber,
+		"iso3":         query.TypeString,
+		"country":      query.TypeString,
+		"nice_country": query.TypeString,
+		"currency":     query.TypeString,
+	},
+}
+
 type CreateCountryRequest struct {
 	Iso3         string `validate:"required,min=3,max=3" json:"iso3"`
 	Country      string `validate:"required,min=3,max=50" json:"country"`
@@ -24,4 +48,5 @@ type UpdateCountryRequest struct {
 	Country      string `json:"country"`      // `validate:"required,min=3,max=50"`
 	Nice_Country string `json:"nice_country"` // `validate:"required,min=3,max=50"`
 	Currency     string `json:"currency"`     // `validate:"required,min=3,max=50"`
+	If_Match     string `json:"-"`
 }
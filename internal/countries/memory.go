@@ -0,0 +1,121 @@
+package countries
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for country data
+
+// MemoryTable is the in-memory table of countries, named after the migration
+const MemoryTable = "country"
+
+// CreateMemoryTables adds the country table to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, nil)
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetCountries(ctx context.Context, tx store.Tx, options *query.Options) ([]Countries, *query.Page, error) {
+	countries, err := memory.Rows[Countries](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetCountries")
+		return nil, nil, err
+	}
+
+	return query.Apply(options, countries, countryValue)
+}
+
+func (r MemoryRepository) GetCountryByID(ctx context.Context, tx store.Tx, countryID uint16) (*Countries, error) {
+	country, ok, err := memory.Get[Countries](memory.Of(tx), MemoryTable, uint64(countryID))
+	if err != nil {
+		r.Log.Error(ctx, "get row with error", "error", err, "func_name", "memory.GetCountryByID")
+		return nil, fmt.Errorf(countryBaseError, countryID, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf(countryNotFoundError, countryID, ErrCountryNotFound)
+	}
+
+	return &country, nil
+}
+
+func (r MemoryRepository) CreateCountry(ctx context.Context, tx store.Tx, country *Countries) (*Countries, error) {
+	updatedAt := time.Now()
+	country.Updated_At = &updatedAt
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *country
+		created.ID = uint8(id)
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create country error", "error", err, "func_name", "memory.CreateCountry")
+		return nil, err
+	}
+
+	country.ID = uint8(id)
+	return country, nil
+}
+
+func (r MemoryRepository) UpdateCountry(ctx context.Context, tx store.Tx, country *Countries) (*Countries, error) {
+	if err := checkUnmodified(tx, country); err != nil {
+		r.Log.Warn(ctx, "country modified since it was read", "error", err, "func_name", "memory.UpdateCountry")
+		return nil, err
+	}
+
+	updatedAt := time.Now()
+	country.Updated_At = &updatedAt
+	if err := memory.Of(tx).Put(MemoryTable, uint64(country.ID), *country); err != nil {
+		r.Log.Error(ctx, "put row with update country error", "error", err, "func_name", "memory.UpdateCountry")
+		return nil, err
+	}
+
+	return country, nil
+}
+
+func (r MemoryRepository) DeleteCountry(ctx context.Context, tx store.Tx, country *Countries) error {
+	if err := checkUnmodified(tx, country); err != nil {
+		r.Log.Warn(ctx, "country modified since it was read", "error", err, "func_name", "memory.DeleteCountry")
+		return err
+	}
+
+	if err := memory.Of(tx).Delete(MemoryTable, uint64(country.ID)); err != nil {
+		r.Log.Error(ctx, "delete row with delete country error", "error", err, "func_name", "memory.DeleteCountry")
+		return err
+	}
+
+	return nil
+}
+
+// checkUnmodified fails with ErrCountryModified when the stored country has
+// another Updated_At than the one read, like the WHERE of MySQLRepository
+func checkUnmodified(tx store.Tx, country *Countries) error {
+	stored, ok, err := memory.Get[Countries](memory.Of(tx), MemoryTable, uint64(country.ID))
+	if err != nil {
+		return fmt.Errorf(countryBaseError, country.ID, err)
+	}
+
+	unmodified := ok && (stored.Updated_At == nil) == (country.Updated_At == nil)
+	if unmodified && stored.Updated_At != nil {
+		unmodified = stored.Updated_At.Equal(*country.Updated_At)
+	}
+	if !unmodified {
+		return fmt.Errorf(countryModifiedError, country.ID, ErrCountryModified)
+	}
+
+	return nil
+}
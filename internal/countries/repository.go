@@ -5,23 +5,51 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"toko-buku-api/pkg/errs"
 	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
 )
 
 // Database access methods for country data
 
-type Repository struct {
+// Repository is the country data access used by the usecase
+type Repository interface {
+	GetCountries(ctx context.Context, tx store.Tx, options *query.Options) ([]Countries, *query.Page, error)
+	GetCountryByID(ctx context.Context, tx store.Tx, countryID uint16) (*Countries, error)
+	CreateCountry(ctx context.Context, tx store.Tx, country *Countries) (*Countries, error)
+	UpdateCountry(ctx context.Context, tx store.Tx, country *Countries) (*Countries, error)
+	DeleteCountry(ctx context.Context, tx store.Tx, country *Countries) error
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
 	DB  *sql.DB
 	Log *logger.Logger
 }
 
 const (
 	countryBaseError     = "country %d: %v"
-	countryNotFoundError = "country %d: not found"
+	countryNotFoundError = "country %d: %w"
+	countryModifiedError = "country %d: %w"
 )
 
-func NewRepository(db *sql.DB, logger *logger.Logger) Repository {
-	return Repository{
+var ErrCountryNotFound = errs.NotFound("not found")
+
+// ErrCountryModified is returned for writes to a country changed since it
+// was read, or whose ETag the client does not match
+var ErrCountryModified = errs.PreconditionFailed("country was modified")
+
+const selectCountries = `SELECT id, updated_at, iso3, country, nice_country, currency FROM country`
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "country", Columns: []string{"id", "updated_at", "iso3", "country", "nice_country", "currency"}},
+}
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
 		DB:  db,
 		Log: logger,
 	}
@@ -31,15 +59,15 @@ func NewRepository(db *sql.DB, logger *logger.Logger) Repository {
 // FindAllComplete(..)
 // FindCompletePersonByID(..)
 
-func (r Repository) GetCountries(ctx context.Context, tx *sql.Tx) ([]Countries, error) {
+func (r MySQLRepository) GetCountries(ctx context.Context, tx store.Tx, options *query.Options) ([]Countries, *query.Page, error) {
 	var funcName = "repository.GetCountries"
 	var countries []Countries
-	query := `SELECT id, updated_at, iso3, country, nice_country, currency FROM countries`
+	selectQuery, args := options.Select(selectCountries, false)
 
-	rows, err := tx.QueryContext(ctx, query)
+	rows, err := store.SQL(tx).QueryContext(ctx, selectQuery, args...)
 	if err != nil {
 		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
@@ -47,7 +75,7 @@ func (r Repository) GetCountries(ctx context.Context, tx *sql.Tx) ([]Countries,
 		country, err := scanIntoGetCountries(rows)
 		if err != nil {
 			r.Log.Error(ctx, "get scan into get countries with error", "error", err, "func_name", funcName)
-			return nil, err
+			return nil, nil, err
 		}
 
 		countries = append(countries, country)
@@ -55,17 +83,42 @@ func (r Repository) GetCountries(ctx context.Context, tx *sql.Tx) ([]Countries,
 
 	rerr := rows.Close()
 	if rerr != nil {
-		r.Log.Error(ctx, "get rows close with error", "error", err, "func_name", funcName)
+		r.Log.Error(ctx, "get rows close with error", "error", rerr, "func_name", funcName)
 
-		return nil, err
+		return nil, nil, rerr
 	}
 
 	if err := rows.Err(); err != nil {
 		r.Log.Error(ctx, "get rows err with error", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, nil, err
+	}
+
+	var total int
+	countQuery, countArgs := options.Count(`SELECT COUNT(*) FROM country`, false)
+	if err := store.SQL(tx).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.Log.Error(ctx, "get count countries with error", "error", err, "func_name", funcName)
+		return nil, nil, err
 	}
 
-	return countries, nil
+	return query.Paginate(options, countries, total, countryValue)
+}
+
+// countryValue returns the value of a QuerySpec field for cursors
+func countryValue(country Countries, field string) any {
+	switch field {
+	case "updated_at":
+		return country.Updated_At
+	case "iso3":
+		return country.Iso3
+	case "country":
+		return country.Country
+	case "nice_country":
+		return country.Nice_Country
+	case "currency":
+		return country.Currency
+	default:
+		return country.ID
+	}
 }
 
 func scanIntoGetCountries(rows *sql.Rows) (country Countries, err error) {
@@ -80,7 +133,7 @@ func scanIntoGetCountries(rows *sql.Rows) (country Countries, err error) {
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return country, fmt.Errorf(countryNotFoundError, country.ID)
+			return country, fmt.Errorf(countryNotFoundError, country.ID, ErrCountryNotFound)
 		}
 		return country, fmt.Errorf(countryBaseError, country.ID, err)
 	}
@@ -88,26 +141,26 @@ func scanIntoGetCountries(rows *sql.Rows) (country Countries, err error) {
 	return
 }
 
-func (r Repository) GetCountryByID(ctx context.Context, tx *sql.Tx, countryID uint16) (country *Countries, err error) {
+func (r MySQLRepository) GetCountryByID(ctx context.Context, tx store.Tx, countryID uint16) (country *Countries, err error) {
 	funcName := "repository.GetCountryByID"
-	query := `SELECT id, updated_at, iso3, country, nice_country, currency FROM countries WHERE id = ?`
+	query := selectCountries + ` WHERE id = ?`
 
-	row := tx.QueryRowContext(ctx, query, countryID)
+	row := store.SQL(tx).QueryRowContext(ctx, query, countryID)
 
-	country, err = scanRowIntoGetCountryByID(row)
+	country, err = scanRowIntoGetCountryByID(row, countryID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			r.Log.Error(ctx, "get scan row into get coutry by id with errors.Is", "error", err, "func_name", funcName)
-			return nil, fmt.Errorf(countryNotFoundError, country.ID)
+		if errors.Is(err, ErrCountryNotFound) {
+			r.Log.Warn(ctx, "get scan row into get country by id with not found", "error", err, "func_name", funcName)
+			return nil, err
 		}
-		r.Log.Error(ctx, "get scan row into get coutry by id with error", "error", err, "func_name", funcName)
-		return nil, fmt.Errorf(countryBaseError, country.ID, err)
+		r.Log.Error(ctx, "get scan row into get country by id with error", "error", err, "func_name", funcName)
+		return nil, err
 	}
 
 	return country, nil
 }
 
-func scanRowIntoGetCountryByID(row *sql.Row) (*Countries, error) {
+func scanRowIntoGetCountryByID(row *sql.Row, countryID uint16) (*Countries, error) {
 	country := Countries{}
 
 	err := row.Scan(
@@ -121,19 +174,19 @@ func scanRowIntoGetCountryByID(row *sql.Row) (*Countries, error) {
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf(countryNotFoundError, country.ID)
+			return nil, fmt.Errorf(countryNotFoundError, countryID, ErrCountryNotFound)
 		}
-		return nil, fmt.Errorf(countryBaseError, country.ID, err)
+		return nil, fmt.Errorf(countryBaseError, countryID, err)
 	}
 
 	return &country, nil
 }
 
-func (r Repository) CreateCountry(ctx context.Context, tx *sql.Tx, country *Countries) (auther *Countries, err error) {
+func (r MySQLRepository) CreateCountry(ctx context.Context, tx store.Tx, country *Countries) (auther *Countries, err error) {
 	funcName := "repository.CreateCountry"
 
-	query := "INSERT INTO countries(id, updated_at, iso3, country, nice_country, currency) VALUES (?, ?, ?, ?, ?, ?)"
-	result, err := tx.ExecContext(ctx, query, country.ID, country.Updated_At, country.Iso3, country.Country, country.Nice_Country, country.Currency)
+	query := "INSERT INTO country(iso3, country, nice_country, currency) VALUES (?, ?, ?, ?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, country.Iso3, country.Country, country.Nice_Country, country.Currency)
 	if err != nil {
 		r.Log.Error(ctx, "get exec context with create country error", "error", err, "func_name", funcName)
 		return nil, err
@@ -148,25 +201,53 @@ func (r Repository) CreateCountry(ctx context.Context, tx *sql.Tx, country *Coun
 	return country, nil
 }
 
-func (r Repository) UpdateCountry(ctx context.Context, tx *sql.Tx, country *Countries) (*Countries, error) {
-	// TODO: Why?
-	query := "UPDATE countries SET iso3 = ?, country = ?, nice_country = ?, currency = ? WHERE id = ?"
-	_, err := tx.ExecContext(ctx, query, country.Iso3, country.Country, country.Currency, country.ID)
+// UpdateCountry writes the country read with its Updated_At, failing with
+// ErrCountryModified when the row changed since. The seeded countries have
+// no updated_at, hence the NULL-safe comparison
+func (r MySQLRepository) UpdateCountry(ctx context.Context, tx store.Tx, country *Countries) (*Countries, error) {
+	funcName := "repository.UpdateCountry"
+
+	query := "UPDATE country SET iso3 = ?, country = ?, nice_country = ?, currency = ?, updated_at = CURRENT_TIMESTAMP(6) WHERE id = ? AND updated_at <=> ?"
+	result, err := store.SQL(tx).ExecContext(ctx, query, country.Iso3, country.Country, country.Nice_Country, country.Currency, country.ID, country.Updated_At)
 	if err != nil {
-		r.Log.Error(ctx, "get exec context with update country error", "error", err, "func_name", "repository.UpdateCountry")
+		r.Log.Error(ctx, "get exec context with update country error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	if err := r.checkUnmodified(ctx, result, country.ID, funcName); err != nil {
 		return nil, err
 	}
 
 	return country, nil
 }
 
-func (r Repository) DeleteCountry(ctx context.Context, tx *sql.Tx, country *Countries) error {
-	query := "DELETE FROM countries WHERE id = ?"
-	_, err := tx.ExecContext(ctx, query, country.ID)
+// DeleteCountry deletes the country read with its Updated_At, failing with
+// ErrCountryModified when the row changed since
+func (r MySQLRepository) DeleteCountry(ctx context.Context, tx store.Tx, country *Countries) error {
+	funcName := "repository.DeleteCountry"
+
+	query := "DELETE FROM country WHERE id = ? AND updated_at <=> ?"
+	result, err := store.SQL(tx).ExecContext(ctx, query, country.ID, country.Updated_At)
 	if err != nil {
-		r.Log.Error(ctx, "get exec context with delete country error", "error", err, "func_name", "repository.DeleteCountry")
+		r.Log.Error(ctx, "get exec context with delete country error", "error", err, "func_name", funcName)
 		return err
 	}
 
+	return r.checkUnmodified(ctx, result, country.ID, funcName)
+}
+
+// checkUnmodified fails with ErrCountryModified when the write matched no
+// row, its updated_at having changed
+func (r MySQLRepository) checkUnmodified(ctx context.Context, result sql.Result, countryID uint8, funcName string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.Log.Error(ctx, "get result rows affected with error", "error", err, "func_name", funcName)
+		return err
+	}
+	if affected == 0 {
+		r.Log.Warn(ctx, "country modified since it was read", "country_id", countryID, "func_name", funcName)
+		return fmt.Errorf(countryModifiedError, countryID, ErrCountryModified)
+	}
+
 	return nil
 }
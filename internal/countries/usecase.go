@@ -2,56 +2,57 @@ package countries
 
 import (
 	"context"
+	"fmt"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/etag"
 	"toko-buku-api/pkg/logger"
-	"toko-buku-api/utils"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type Usecase struct {
 	Repo     Repository
+	Tx       store.Beginner
 	Log      *logger.Logger
 	Validate *validator.Validate
 }
 
-func NewUsecase(repo Repository, logger *logger.Logger, validate *validator.Validate) Usecase {
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate) Usecase {
 	return Usecase{
 		Repo:     repo,
+		Tx:       tx,
 		Log:      logger,
 		Validate: validate,
 	}
 }
 
-func (u *Usecase) GetCountries(ctx context.Context) ([]Countries, error) {
+func (u *Usecase) GetCountries(ctx context.Context, options *query.Options) ([]Countries, *query.Page, error) {
 	funcName := "usecase.GetCountries"
 
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to get countries: repo db begin", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
-
-	countrys, err := u.Repo.GetCountries(ctx, tx)
+	var countries []Countries
+	var page *query.Page
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		countries, page, err = u.Repo.GetCountries(ctx, tx, options)
+		return err
+	})
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to get countries: not found", "error", err, "func_name", funcName)
-		return nil, err
+		u.Log.Warn(ctx, "failed request body to get countries", "error", err, "func_name", funcName)
+		return nil, nil, err
 	}
 
-	return countrys, nil
+	return countries, page, nil
 }
 
 func (u *Usecase) GetCountryByID(ctx context.Context, countryID uint16) (*Countries, error) {
 	funcName := "usecase.GetCountryByID"
 
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to get country by id", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
-
-	country, err := u.Repo.GetCountryByID(ctx, tx, countryID)
+	var country *Countries
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		country, err = u.Repo.GetCountryByID(ctx, tx, countryID)
+		return err
+	})
 	if err != nil {
 		u.Log.Warn(ctx, "failed request body to get country by id: not found by id", "error", err, "func_name", funcName)
 		return nil, err
@@ -66,24 +67,19 @@ func (u *Usecase) CreateCountry(ctx context.Context, request *CreateCountryReque
 	err := u.Validate.Struct(request)
 	if err != nil {
 		u.Log.Warn(ctx, "invalid request body to create country", "error", err, "func_name", funcName)
-		return nil, err
+		return nil, errs.Invalid(err)
 	}
 
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to create country: repo db begin", "error", err, "func_name", funcName)
-		return nil, err
-	}
-	defer utils.CommitOrRollback(tx)
-
-	createdCountry := &Countries{
-		Iso3:         request.Iso3,
-		Country:      request.Country,
-		Nice_Country: request.Nice_Country,
-		Currency:     request.Currency,
-	}
-
-	createdCountry, err = u.Repo.CreateCountry(ctx, tx, createdCountry)
+	var createdCountry *Countries
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) (err error) {
+		createdCountry, err = u.Repo.CreateCountry(ctx, tx, &Countries{
+			Iso3:         request.Iso3,
+			Country:      request.Country,
+			Nice_Country: request.Nice_Country,
+			Currency:     request.Currency,
+		})
+		return err
+	})
 	if err != nil {
 		u.Log.Warn(ctx, "invalid request body to create country", "error", err, "func_name", funcName)
 		return nil, err
@@ -98,61 +94,91 @@ func (u *Usecase) UpdateCountry(ctx context.Context, request *UpdateCountryReque
 	err := u.Validate.Struct(request)
 	if err != nil {
 		u.Log.Warn(ctx, "invalid request body to update country", "error", err, "func_name", funcName)
-		return nil, err
-	}
-
-	tx, err := u.Repo.DB.Begin()
+		return nil, errs.Invalid(err)
+	}
+
+	var updatedCountry *Countries
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		oldCountry, err := u.Repo.GetCountryByID(ctx, tx, uint16(request.ID))
+		if err != nil {
+			u.Log.Warn(ctx, "failed request body to update country", "error", err, "func_name", funcName)
+			return err
+		}
+
+		if err := checkETag(oldCountry, request.If_Match); err != nil {
+			return err
+		}
+
+		if request.Country != "" {
+			oldCountry.Country = request.Country
+		}
+
+		if request.Currency != "" {
+			oldCountry.Currency = request.Currency
+		}
+		if request.Iso3 != "" {
+			oldCountry.Iso3 = request.Iso3
+		}
+
+		if request.Nice_Country != "" {
+			oldCountry.Nice_Country = request.Nice_Country
+		}
+
+		if _, err := u.Repo.UpdateCountry(ctx, tx, oldCountry); err != nil {
+			return err
+		}
+
+		// read it back for its new updated_at
+		updatedCountry, err = u.Repo.GetCountryByID(ctx, tx, uint16(oldCountry.ID))
+		return err
+	})
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to update country: repo db begin", "error", err, "func_name", funcName)
+		u.Log.Warn(ctx, "failed request body to update country: repo UpdateCountry", "error", err, "func_name", funcName)
 		return nil, err
 	}
-	defer utils.CommitOrRollback(tx)
 
-	oldCountry, err := u.Repo.GetCountryByID(ctx, tx, uint16(request.ID))
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to update country", "error", err, "func_name", funcName)
-		return nil, err
-	}
+	return updatedCountry, nil
+}
 
-	if request.Country != "" {
-		oldCountry.Country = request.Country
-	}
+// DeleteCountry deletes the country, when its ETag matches ifMatch unless
+// ifMatch is empty
+func (u *Usecase) DeleteCountry(ctx context.Context, countryID uint16, ifMatch string) error {
+	funcName := "usecase.DeleteCountry"
 
-	if request.Currency != "" {
-		oldCountry.Currency = request.Currency
-	}
-	if request.Iso3 != "" {
-		oldCountry.Iso3 = request.Iso3
-	}
+	err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		country, err := u.Repo.GetCountryByID(ctx, tx, countryID)
+		if err != nil {
+			return err
+		}
 
-	if request.Nice_Country != "" {
-		oldCountry.Nice_Country = request.Nice_Country
-	}
+		if err := checkETag(country, ifMatch); err != nil {
+			return err
+		}
 
-	updatedCountry, err := u.Repo.UpdateCountry(ctx, tx, oldCountry)
+		return u.Repo.DeleteCountry(ctx, tx, country)
+	})
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to update country: repo UpdateCountry", "error", err, "func_name", funcName)
-		return nil, err
+		u.Log.Warn(ctx, "failed request body to delete country", "error", err, "func_name", funcName)
+		return err
 	}
 
-	return updatedCountry, nil
+	return nil
 }
 
-func (u *Usecase) DeleteCountry(ctx context.Context, countryID uint16) error {
-	funcName := "usecase.DeleteCountry"
-
-	tx, err := u.Repo.DB.Begin()
-	if err != nil {
-		u.Log.Warn(ctx, "failed request body to delete country: repo db begin", "error", err, "func_name", funcName)
-		return err
+// checkETag fails with ErrCountryModified when ifMatch, an If-Match header,
+// is set and does not match the ETag of the country
+func checkETag(country *Countries, ifMatch string) error {
+	if ifMatch == "" {
+		return nil
 	}
-	defer utils.CommitOrRollback(tx)
 
-	country, err := u.Repo.GetCountryByID(ctx, tx, countryID)
+	tag, err := etag.Of(country)
 	if err != nil {
-		u.Log.Warn(ctx, "failed request body to delete country", "error", err, "func_name", funcName)
 		return err
 	}
+	if !etag.Match(ifMatch, tag, false) {
+		return fmt.Errorf(countryModifiedError, country.ID, ErrCountryModified)
+	}
 
-	return u.Repo.DeleteCountry(ctx, tx, country)
+	return nil
 }
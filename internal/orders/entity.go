@@ -0,0 +1,77 @@
+package orders
+
+import "time"
+
+// Data models and structs specific to order functionality
+
+const (
+	StatusProcessed = "processed"
+	StatusShipped   = "shipped"
+	StatusReceived  = "received"
+	StatusCancelled = "cancelled"
+)
+
+// MinCount and MaxCount bound the total pieces of one order,
+// see the `order.count` column comment
+const (
+	MinCount = 1
+	MaxCount = 15
+)
+
+type Orders struct {
+	ID                uint64
+	Created_At        time.Time
+	Updated_At        *time.Time
+	Deleted_At        *time.Time `json:"-"`
+	Created_By        string
+	X_Idempotency_Key string
+	Status            string
+	Count             uint8
+	Books             []OrderBooks
+}
+
+// OrderBooks is a row of `order_has_book` joined with its book
+type OrderBooks struct {
+	Book_Id  uint32
+	Quantity uint8
+	Title    string
+	Sku      string
+	Price    float64
+}
+
+type CreateOrderRequest struct {
+	Created_By        string                   `validate:"max=100" json:"-"`
+	X_Idempotency_Key string                   `validate:"required,max=38" json:"-"`
+	Books             []CreateOrderBookRequest `validate:"required,min=1,max=15,unique=Book_Id,dive" json:"books"`
+}
+
+type CreateOrderBookRequest struct {
+	Book_Id  uint32 `validate:"required" json:"book_id"`
+	Quantity uint8  `validate:"required,min=1,max=15" json:"quantity"`
+}
+
+// OrderStatusHistories is a row of `order_status_history`
+type OrderStatusHistories struct {
+	ID          uint64
+	Created_At  time.Time
+	Order_Id    uint64
+	From_Status *string
+	To_Status   string
+	Changed_By  *string
+	Note        *string
+}
+
+// OrderResponses is a row of `order_response`, the response to the create of
+// an order, replayed to the requests repeating its idempotency key
+type OrderResponses struct {
+	Order_Id uint64
+	Status   int
+	Body     []byte
+}
+
+type TransitionOrderRequest struct {
+	ID         uint64 `json:"id"`
+	Status     string `validate:"required,oneof=processed shipped received cancelled" json:"status"`
+	Changed_By string `validate:"omitempty,max=100" json:"-"`
+	Note       string `validate:"omitempty,max=255" json:"note"`
+}
@@ -0,0 +1,375 @@
+package orders
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"toko-buku-api/internal/books"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for order data
+
+// In-memory tables of orders, named after the migrations
+const (
+	MemoryTable              = "order"
+	MemoryOrderBookTable     = "order_has_book"
+	MemoryStatusHistoryTable = "order_status_history"
+	MemoryResponseTable      = "order_response"
+)
+
+// memoryOrderBook is a row of the in-memory `order_has_book` table
+type memoryOrderBook struct {
+	Order_Id uint64
+	Book_Id  uint32
+	Quantity uint8
+}
+
+// CreateMemoryTables adds the order tables to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, nil)
+	db.CreateTable(MemoryOrderBookTable, func(row any) map[string]uint64 {
+		orderBook := row.(memoryOrderBook)
+		return map[string]uint64{
+			MemoryTable:       orderBook.Order_Id,
+			books.MemoryTable: uint64(orderBook.Book_Id),
+		}
+	})
+	db.CreateTable(MemoryStatusHistoryTable, func(row any) map[string]uint64 {
+		return map[string]uint64{MemoryTable: row.(OrderStatusHistories).Order_Id}
+	})
+	db.CreateTable(MemoryResponseTable, func(row any) map[string]uint64 {
+		return map[string]uint64{MemoryTable: row.(OrderResponses).Order_Id}
+	})
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetOrderById(ctx context.Context, tx store.Tx, orderId uint64) (*Orders, error) {
+	funcName := "memory.GetOrderById"
+
+	order, ok, err := memory.Get[Orders](memory.Of(tx), MemoryTable, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get order by id with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(orderBaseError, orderId, err)
+	}
+	if !ok || order.Deleted_At != nil {
+		r.Log.Warn(ctx, fmt.Sprintf(orderNotFoundError, orderId), "func_name", funcName)
+		return nil, ErrOrderNotFound
+	}
+
+	order.Books, err = getMemoryOrderBooks(tx, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get order books with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(orderBaseError, orderId, err)
+	}
+
+	return &order, nil
+}
+
+// GetOrderByIdempotencyKey returns the order the principal createdBy
+// created with the key, or ErrOrderNotFound
+func (r MemoryRepository) GetOrderByIdempotencyKey(ctx context.Context, tx store.Tx, createdBy, key string) (*Orders, error) {
+	funcName := "memory.GetOrderByIdempotencyKey"
+
+	order, ok, err := findOrderByIdempotencyKey(tx, func(order Orders) bool {
+		return order.Created_By == createdBy && order.X_Idempotency_Key == key
+	})
+	if err != nil {
+		r.Log.Error(ctx, "get order by idempotency key with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+
+	order.Books, err = getMemoryOrderBooks(tx, order.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get order books with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// IdempotencyKeyUsed reports whether any principal created an order with
+// the key
+func (r MemoryRepository) IdempotencyKeyUsed(ctx context.Context, tx store.Tx, key string) (bool, error) {
+	_, ok, err := findOrderByIdempotencyKey(tx, func(order Orders) bool {
+		return order.X_Idempotency_Key == key
+	})
+	if err != nil {
+		r.Log.Error(ctx, "get order by idempotency key with error", "error", err, "func_name", "memory.IdempotencyKeyUsed")
+		return false, err
+	}
+
+	return ok, nil
+}
+
+// findOrderByIdempotencyKey returns the first order not deleted matching
+func findOrderByIdempotencyKey(tx store.Tx, match func(order Orders) bool) (Orders, bool, error) {
+	orders, err := memory.Rows[Orders](memory.Of(tx), MemoryTable)
+	if err != nil {
+		return Orders{}, false, err
+	}
+
+	for _, order := range orders {
+		if order.Deleted_At == nil && match(order) {
+			return order, true, nil
+		}
+	}
+
+	return Orders{}, false, nil
+}
+
+// getMemoryOrderBooks joins the order books with their book, ordered by book id
+func getMemoryOrderBooks(tx store.Tx, orderId uint64) ([]OrderBooks, error) {
+	rows, err := memory.Rows[memoryOrderBook](memory.Of(tx), MemoryOrderBookTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderBooks []OrderBooks
+	for _, row := range rows {
+		if row.Order_Id != orderId {
+			continue
+		}
+
+		book, _, err := memory.Get[books.Books](memory.Of(tx), books.MemoryTable, uint64(row.Book_Id))
+		if err != nil {
+			return nil, err
+		}
+
+		orderBooks = append(orderBooks, OrderBooks{
+			Book_Id:  row.Book_Id,
+			Quantity: row.Quantity,
+			Title:    book.Title,
+			Sku:      book.Sku,
+			Price:    book.Price,
+		})
+	}
+
+	slices.SortFunc(orderBooks, func(a, b OrderBooks) int {
+		return cmp.Compare(a.Book_Id, b.Book_Id)
+	})
+
+	return orderBooks, nil
+}
+
+// CreateOrder inserts the order row, returning ErrIdempotencyKeyExists when
+// another order of the same principal already claimed the key
+func (r MemoryRepository) CreateOrder(ctx context.Context, tx store.Tx, order *Orders) (*Orders, error) {
+	funcName := "memory.CreateOrder"
+
+	claimed := func(other Orders) bool {
+		return other.Created_By == order.Created_By && other.X_Idempotency_Key == order.X_Idempotency_Key
+	}
+	if _, ok, err := findOrderByIdempotencyKey(tx, claimed); err != nil || ok {
+		if ok {
+			return nil, ErrIdempotencyKeyExists
+		}
+		r.Log.Error(ctx, "get order by idempotency key with create order error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	order.Created_At = time.Now()
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *order
+		created.ID = id
+		created.Books = nil
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create order error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	order.ID = id
+	return order, nil
+}
+
+func (r MemoryRepository) CreateOrderBook(ctx context.Context, tx store.Tx, orderId uint64, orderBook *OrderBooks) error {
+	_, err := memory.Of(tx).Insert(MemoryOrderBookTable, func(uint64) any {
+		return memoryOrderBook{
+			Order_Id: orderId,
+			Book_Id:  orderBook.Book_Id,
+			Quantity: orderBook.Quantity,
+		}
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create order book error", "error", err, "func_name", "memory.CreateOrderBook")
+		return err
+	}
+
+	return nil
+}
+
+// DecrementStock takes quantity pieces off the stock of the book, the
+// transaction already holds the database exclusively
+func (r MemoryRepository) DecrementStock(ctx context.Context, tx store.Tx, bookId uint32, quantity uint8) error {
+	funcName := "memory.DecrementStock"
+
+	book, ok, err := memory.Get[books.Books](memory.Of(tx), books.MemoryTable, uint64(bookId))
+	if err != nil {
+		r.Log.Error(ctx, "get row with lock book error", "error", err, "func_name", funcName)
+		return err
+	}
+	if !ok || book.Deleted_At != nil {
+		return fmt.Errorf("book %d: %w", bookId, ErrBookNotFound)
+	}
+
+	if book.Stock < uint32(quantity) {
+		return fmt.Errorf("book %d: %w", bookId, ErrInsufficientStock)
+	}
+
+	book.Stock -= uint32(quantity)
+	if err := memory.Of(tx).Put(books.MemoryTable, uint64(bookId), book); err != nil {
+		r.Log.Error(ctx, "put row with decrement stock error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
+
+// LockOrderStatus returns the current status of the order, the transaction
+// already holds the database exclusively
+func (r MemoryRepository) LockOrderStatus(ctx context.Context, tx store.Tx, orderId uint64) (string, error) {
+	funcName := "memory.LockOrderStatus"
+
+	order, ok, err := memory.Get[Orders](memory.Of(tx), MemoryTable, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get row with lock order error", "error", err, "func_name", funcName)
+		return "", fmt.Errorf(orderBaseError, orderId, err)
+	}
+	if !ok || order.Deleted_At != nil {
+		r.Log.Warn(ctx, fmt.Sprintf(orderNotFoundError, orderId), "func_name", funcName)
+		return "", ErrOrderNotFound
+	}
+
+	return order.Status, nil
+}
+
+func (r MemoryRepository) UpdateOrderStatus(ctx context.Context, tx store.Tx, orderId uint64, status string) error {
+	funcName := "memory.UpdateOrderStatus"
+
+	order, ok, err := memory.Get[Orders](memory.Of(tx), MemoryTable, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get row with update order status error", "error", err, "func_name", funcName)
+		return fmt.Errorf(orderBaseError, orderId, err)
+	}
+	if !ok {
+		return ErrOrderNotFound
+	}
+
+	updatedAt := time.Now()
+	order.Status = status
+	order.Updated_At = &updatedAt
+	if err := memory.Of(tx).Put(MemoryTable, orderId, order); err != nil {
+		r.Log.Error(ctx, "put row with update order status error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
+
+// RestoreStock puts the quantities of every book of the order back in stock
+func (r MemoryRepository) RestoreStock(ctx context.Context, tx store.Tx, orderId uint64) error {
+	funcName := "memory.RestoreStock"
+
+	rows, err := memory.Rows[memoryOrderBook](memory.Of(tx), MemoryOrderBookTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with restore stock error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	for _, row := range rows {
+		if row.Order_Id != orderId {
+			continue
+		}
+
+		book, ok, err := memory.Get[books.Books](memory.Of(tx), books.MemoryTable, uint64(row.Book_Id))
+		if err != nil || !ok {
+			continue
+		}
+
+		book.Stock += uint32(row.Quantity)
+		if err := memory.Of(tx).Put(books.MemoryTable, uint64(row.Book_Id), book); err != nil {
+			r.Log.Error(ctx, "put row with restore stock error", "error", err, "func_name", funcName)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r MemoryRepository) CreateStatusHistory(ctx context.Context, tx store.Tx, history *OrderStatusHistories) error {
+	history.Created_At = time.Now()
+	id, err := memory.Of(tx).Insert(MemoryStatusHistoryTable, func(id uint64) any {
+		created := *history
+		created.ID = id
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create status history error", "error", err, "func_name", "memory.CreateStatusHistory")
+		return err
+	}
+
+	history.ID = id
+	return nil
+}
+
+func (r MemoryRepository) GetStatusHistories(ctx context.Context, tx store.Tx, orderId uint64) ([]OrderStatusHistories, error) {
+	rows, err := memory.Rows[OrderStatusHistories](memory.Of(tx), MemoryStatusHistoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetStatusHistories")
+		return nil, err
+	}
+
+	var histories []OrderStatusHistories
+	for _, history := range rows {
+		if history.Order_Id == orderId {
+			histories = append(histories, history)
+		}
+	}
+
+	return histories, nil
+}
+
+// CreateOrderResponse stores the response to the create of the order, keyed
+// by the order id like the primary key in MySQL
+func (r MemoryRepository) CreateOrderResponse(ctx context.Context, tx store.Tx, response *OrderResponses) error {
+	if err := memory.Of(tx).Put(MemoryResponseTable, response.Order_Id, *response); err != nil {
+		r.Log.Error(ctx, "put row with create order response error", "error", err, "func_name", "memory.CreateOrderResponse")
+		return err
+	}
+
+	return nil
+}
+
+// GetOrderResponse returns the response to the create of the order, or
+// ErrOrderResponseNotFound
+func (r MemoryRepository) GetOrderResponse(ctx context.Context, tx store.Tx, orderId uint64) (*OrderResponses, error) {
+	response, ok, err := memory.Get[OrderResponses](memory.Of(tx), MemoryResponseTable, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get order response with error", "error", err, "func_name", "memory.GetOrderResponse")
+		return nil, fmt.Errorf(orderBaseError, orderId, err)
+	}
+	if !ok {
+		return nil, ErrOrderResponseNotFound
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,366 @@
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Database access methods for order data
+
+// Repository is the order data access used by the usecase
+type Repository interface {
+	GetOrderById(ctx context.Context, tx store.Tx, orderId uint64) (*Orders, error)
+	GetOrderByIdempotencyKey(ctx context.Context, tx store.Tx, createdBy, key string) (*Orders, error)
+	IdempotencyKeyUsed(ctx context.Context, tx store.Tx, key string) (bool, error)
+	CreateOrder(ctx context.Context, tx store.Tx, order *Orders) (*Orders, error)
+	CreateOrderBook(ctx context.Context, tx store.Tx, orderId uint64, orderBook *OrderBooks) error
+	DecrementStock(ctx context.Context, tx store.Tx, bookId uint32, quantity uint8) error
+	LockOrderStatus(ctx context.Context, tx store.Tx, orderId uint64) (string, error)
+	UpdateOrderStatus(ctx context.Context, tx store.Tx, orderId uint64, status string) error
+	RestoreStock(ctx context.Context, tx store.Tx, orderId uint64) error
+	CreateStatusHistory(ctx context.Context, tx store.Tx, history *OrderStatusHistories) error
+	GetStatusHistories(ctx context.Context, tx store.Tx, orderId uint64) ([]OrderStatusHistories, error)
+	CreateOrderResponse(ctx context.Context, tx store.Tx, response *OrderResponses) error
+	GetOrderResponse(ctx context.Context, tx store.Tx, orderId uint64) (*OrderResponses, error)
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
+	DB  *sql.DB
+	Log *logger.Logger
+}
+
+const (
+	orderBaseError     = "order %d: %v"
+	orderNotFoundError = "order %d: not found"
+)
+
+// mysqlDuplicateEntry is the MySQL error number for a unique key violation
+const mysqlDuplicateEntry = 1062
+
+var (
+	ErrOrderNotFound         = errs.NotFound("order not found")
+	ErrBookNotFound          = errs.Validation("book not found")
+	ErrInsufficientStock     = errs.Conflict("insufficient book stock")
+	ErrOrderCount            = errs.Validation(fmt.Sprintf("order count must be between %d and %d pcs", MinCount, MaxCount))
+	ErrIdempotencyKeyExists  = errs.Conflict("idempotency key already exists")
+	ErrIdempotencyKeyPayload = errs.Validation("idempotency key was used with a different request")
+	ErrIdempotencyKeyOwner   = errs.Conflict("idempotency key was used by another client")
+	ErrIllegalTransition     = errs.Conflict("illegal order status transition")
+	ErrOrderResponseNotFound = errs.NotFound("order response not found")
+)
+
+const selectOrders = "SELECT id, created_at, updated_at, created_by, x_idempotency_key, status, count FROM `order` WHERE deleted_at IS NULL"
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "order", Columns: []string{"id", "created_at", "updated_at", "deleted_at", "created_by", "x_idempotency_key", "status", "count"}},
+	{Name: "order_has_book", Columns: []string{"order_id", "book_id", "quantity"}},
+	{Name: "order_status_history", Columns: []string{"id", "created_at", "order_id", "from_status", "to_status", "changed_by", "note"}},
+	{Name: "order_response", Columns: []string{"order_id", "status", "body"}},
+	{Name: "book", Columns: []string{"id", "deleted_at", "title", "sku", "price", "stock"}},
+}
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+func (r MySQLRepository) GetOrderById(ctx context.Context, tx store.Tx, orderId uint64) (*Orders, error) {
+	funcName := "repository.GetOrderById"
+
+	order, err := r.getOrder(ctx, tx, selectOrders+" AND id = ?", orderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(orderNotFoundError, orderId), "func_name", funcName)
+			return nil, ErrOrderNotFound
+		}
+		r.Log.Error(ctx, "get order by id with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(orderBaseError, orderId, err)
+	}
+
+	return order, nil
+}
+
+// GetOrderByIdempotencyKey returns the order the principal createdBy
+// created with the key, or ErrOrderNotFound
+func (r MySQLRepository) GetOrderByIdempotencyKey(ctx context.Context, tx store.Tx, createdBy, key string) (*Orders, error) {
+	funcName := "repository.GetOrderByIdempotencyKey"
+
+	order, err := r.getOrder(ctx, tx, selectOrders+" AND created_by = ? AND x_idempotency_key = ?", createdBy, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		r.Log.Error(ctx, "get order by idempotency key with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// IdempotencyKeyUsed reports whether any principal created an order with
+// the key
+func (r MySQLRepository) IdempotencyKeyUsed(ctx context.Context, tx store.Tx, key string) (bool, error) {
+	var used bool
+	query := "SELECT EXISTS (SELECT 1 FROM `order` WHERE x_idempotency_key = ? AND deleted_at IS NULL)"
+	err := store.SQL(tx).QueryRowContext(ctx, query, key).Scan(&used)
+	if err != nil {
+		r.Log.Error(ctx, "get query row context with idempotency key used error", "error", err, "func_name", "repository.IdempotencyKeyUsed")
+		return false, err
+	}
+
+	return used, nil
+}
+
+func (r MySQLRepository) getOrder(ctx context.Context, tx store.Tx, query string, args ...any) (*Orders, error) {
+	var order Orders
+	err := store.SQL(tx).QueryRowContext(ctx, query, args...).Scan(
+		&order.ID,
+		&order.Created_At,
+		&order.Updated_At,
+		&order.Created_By,
+		&order.X_Idempotency_Key,
+		&order.Status,
+		&order.Count,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Books, err = r.getOrderBooks(ctx, tx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (r MySQLRepository) getOrderBooks(ctx context.Context, tx store.Tx, orderId uint64) ([]OrderBooks, error) {
+	var orderBooks []OrderBooks
+	query := `SELECT ob.book_id, ob.quantity, b.title, b.sku, b.price
+		FROM order_has_book ob
+		JOIN book b ON ob.book_id = b.id
+		WHERE ob.order_id = ?
+		ORDER BY ob.book_id`
+
+	rows, err := store.SQL(tx).QueryContext(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderBook OrderBooks
+		if err := rows.Scan(&orderBook.Book_Id, &orderBook.Quantity, &orderBook.Title, &orderBook.Sku, &orderBook.Price); err != nil {
+			return nil, err
+		}
+
+		orderBooks = append(orderBooks, orderBook)
+	}
+
+	return orderBooks, rows.Err()
+}
+
+// CreateOrder inserts the order row, returning ErrIdempotencyKeyExists when
+// a concurrent request of the same principal already claimed the key
+func (r MySQLRepository) CreateOrder(ctx context.Context, tx store.Tx, order *Orders) (*Orders, error) {
+	funcName := "repository.CreateOrder"
+
+	query := "INSERT INTO `order`(created_by, x_idempotency_key, status, count) VALUES (?, ?, ?, ?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, order.Created_By, order.X_Idempotency_Key, order.Status, order.Count)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return nil, ErrIdempotencyKeyExists
+		}
+		r.Log.Error(ctx, "get exec context with create order error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	orderId, err := result.LastInsertId()
+	if err != nil {
+		r.Log.Error(ctx, "get result last insert id with create order error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	order.ID = uint64(orderId)
+	return order, nil
+}
+
+func (r MySQLRepository) CreateOrderBook(ctx context.Context, tx store.Tx, orderId uint64, orderBook *OrderBooks) error {
+	query := "INSERT INTO order_has_book(order_id, book_id, quantity) VALUES (?, ?, ?)"
+	_, err := store.SQL(tx).ExecContext(ctx, query, orderId, orderBook.Book_Id, orderBook.Quantity)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create order book error", "error", err, "func_name", "repository.CreateOrderBook")
+		return err
+	}
+
+	return nil
+}
+
+// DecrementStock locks the book row and takes quantity pieces off its stock
+func (r MySQLRepository) DecrementStock(ctx context.Context, tx store.Tx, bookId uint32, quantity uint8) error {
+	funcName := "repository.DecrementStock"
+
+	var stock uint32
+	query := "SELECT stock FROM book WHERE id = ? AND deleted_at IS NULL FOR UPDATE"
+	err := store.SQL(tx).QueryRowContext(ctx, query, bookId).Scan(&stock)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("book %d: %w", bookId, ErrBookNotFound)
+		}
+		r.Log.Error(ctx, "get query row context with lock book error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	if stock < uint32(quantity) {
+		return fmt.Errorf("book %d: %w", bookId, ErrInsufficientStock)
+	}
+
+	_, err = store.SQL(tx).ExecContext(ctx, "UPDATE book SET stock = stock - ? WHERE id = ?", quantity, bookId)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with decrement stock error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
+
+// LockOrderStatus locks the order row for the rest of the transaction and
+// returns its current status
+func (r MySQLRepository) LockOrderStatus(ctx context.Context, tx store.Tx, orderId uint64) (string, error) {
+	funcName := "repository.LockOrderStatus"
+
+	var status string
+	query := "SELECT status FROM `order` WHERE id = ? AND deleted_at IS NULL FOR UPDATE"
+	err := store.SQL(tx).QueryRowContext(ctx, query, orderId).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(orderNotFoundError, orderId), "func_name", funcName)
+			return "", ErrOrderNotFound
+		}
+		r.Log.Error(ctx, "get query row context with lock order error", "error", err, "func_name", funcName)
+		return "", fmt.Errorf(orderBaseError, orderId, err)
+	}
+
+	return status, nil
+}
+
+func (r MySQLRepository) UpdateOrderStatus(ctx context.Context, tx store.Tx, orderId uint64, status string) error {
+	query := "UPDATE `order` SET status = ? WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, status, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with update order status error", "error", err, "func_name", "repository.UpdateOrderStatus")
+		return err
+	}
+
+	return nil
+}
+
+// RestoreStock puts the quantities of every book of the order back in stock
+func (r MySQLRepository) RestoreStock(ctx context.Context, tx store.Tx, orderId uint64) error {
+	query := `UPDATE book b
+		JOIN order_has_book ob ON ob.book_id = b.id
+		SET b.stock = b.stock + ob.quantity
+		WHERE ob.order_id = ?`
+	_, err := store.SQL(tx).ExecContext(ctx, query, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with restore stock error", "error", err, "func_name", "repository.RestoreStock")
+		return err
+	}
+
+	return nil
+}
+
+func (r MySQLRepository) CreateStatusHistory(ctx context.Context, tx store.Tx, history *OrderStatusHistories) error {
+	query := "INSERT INTO order_status_history(order_id, from_status, to_status, changed_by, note) VALUES (?, ?, ?, ?, ?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, history.Order_Id, history.From_Status, history.To_Status, history.Changed_By, history.Note)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create status history error", "error", err, "func_name", "repository.CreateStatusHistory")
+		return err
+	}
+
+	historyId, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	history.ID = uint64(historyId)
+	return nil
+}
+
+func (r MySQLRepository) GetStatusHistories(ctx context.Context, tx store.Tx, orderId uint64) ([]OrderStatusHistories, error) {
+	funcName := "repository.GetStatusHistories"
+	var histories []OrderStatusHistories
+	query := `SELECT id, created_at, order_id, from_status, to_status, changed_by, note
+		FROM order_status_history
+		WHERE order_id = ?
+		ORDER BY id`
+
+	rows, err := store.SQL(tx).QueryContext(ctx, query, orderId)
+	if err != nil {
+		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var history OrderStatusHistories
+		if err := rows.Scan(
+			&history.ID,
+			&history.Created_At,
+			&history.Order_Id,
+			&history.From_Status,
+			&history.To_Status,
+			&history.Changed_By,
+			&history.Note,
+		); err != nil {
+			r.Log.Error(ctx, "get scan into get status histories with error", "error", err, "func_name", funcName)
+			return nil, err
+		}
+
+		histories = append(histories, history)
+	}
+
+	return histories, rows.Err()
+}
+
+// CreateOrderResponse stores the response to the create of the order
+func (r MySQLRepository) CreateOrderResponse(ctx context.Context, tx store.Tx, response *OrderResponses) error {
+	query := "INSERT INTO order_response(order_id, status, body) VALUES (?, ?, ?)"
+	_, err := store.SQL(tx).ExecContext(ctx, query, response.Order_Id, response.Status, response.Body)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create order response error", "error", err, "func_name", "repository.CreateOrderResponse")
+		return err
+	}
+
+	return nil
+}
+
+// GetOrderResponse returns the response to the create of the order, or
+// ErrOrderResponseNotFound for the orders created before it was stored
+func (r MySQLRepository) GetOrderResponse(ctx context.Context, tx store.Tx, orderId uint64) (*OrderResponses, error) {
+	funcName := "repository.GetOrderResponse"
+
+	response := OrderResponses{Order_Id: orderId}
+	query := "SELECT status, body FROM order_response WHERE order_id = ?"
+	err := store.SQL(tx).QueryRowContext(ctx, query, orderId).Scan(&response.Status, &response.Body)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderResponseNotFound
+		}
+		r.Log.Error(ctx, "get order response with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(orderBaseError, orderId, err)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,21 @@
+package orders
+
+// Order status state machine
+
+// transitions lists the statuses each status may move to; received and
+// cancelled are final
+var transitions = map[string][]string{
+	StatusProcessed: {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusReceived, StatusCancelled},
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
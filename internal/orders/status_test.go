@@ -0,0 +1,29 @@
+package orders
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	testCases := []struct {
+		from     string
+		to       string
+		expected bool
+	}{
+		{from: StatusProcessed, to: StatusShipped, expected: true},
+		{from: StatusProcessed, to: StatusCancelled, expected: true},
+		{from: StatusShipped, to: StatusReceived, expected: true},
+		{from: StatusShipped, to: StatusCancelled, expected: true},
+		{from: StatusProcessed, to: StatusReceived, expected: false},
+		{from: StatusProcessed, to: StatusProcessed, expected: false},
+		{from: StatusShipped, to: StatusProcessed, expected: false},
+		{from: StatusReceived, to: StatusCancelled, expected: false},
+		{from: StatusCancelled, to: StatusProcessed, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			if got := CanTransition(tc.from, tc.to); got != tc.expected {
+				t.Fatalf("invalid transition: got %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
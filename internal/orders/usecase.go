@@ -0,0 +1,332 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Core business logic for order operations
+
+type Usecase struct {
+	Repo     Repository
+	Tx       store.Beginner
+	Log      *logger.Logger
+	Validate *validator.Validate
+	// Policy tells whose orders a principal may read
+	Policy auth.Policy
+}
+
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate, policy auth.Policy) Usecase {
+	return Usecase{
+		Repo:     repo,
+		Tx:       tx,
+		Log:      logger,
+		Validate: validate,
+		Policy:   policy,
+	}
+}
+
+// GetOrderById loads the order, not found when claims may not read it.
+func (u *Usecase) GetOrderById(ctx context.Context, claims auth.Claims, orderId uint64) (*Orders, error) {
+	funcName := "usecase.GetOrderById"
+
+	var order *Orders
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		order, err = u.getReadableOrder(ctx, tx, claims, orderId)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get order by id: not found by id", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CreateOrder places the order and decrements the stock of its books in one
+// transaction, returning the response to send, stored with the order. A
+// request repeating an idempotency key of its principal gets the original
+// response back with replayed set, instead of a duplicate, whatever the
+// order became since. The keys of the other principals are refused.
+func (u *Usecase) CreateOrder(ctx context.Context, request *CreateOrderRequest) (response *OrderResponses, replayed bool, err error) {
+	funcName := "usecase.CreateOrder"
+
+	err = u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to create order", "error", err, "func_name", funcName)
+		return nil, false, errs.Invalid(err)
+	}
+
+	count := 0
+	for _, book := range request.Books {
+		count += int(book.Quantity)
+	}
+	if count < MinCount || count > MaxCount {
+		u.Log.Warn(ctx, "invalid request body to create order: count out of range", "count", count, "func_name", funcName)
+		return nil, false, ErrOrderCount
+	}
+
+	response, err = u.createOrder(ctx, request, uint8(count))
+	if errors.Is(err, ErrIdempotencyKeyExists) {
+		response, err = u.replayOrder(ctx, request)
+		return response, err == nil, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, false, nil
+}
+
+// newOrderResponse returns the response to the create of the order, the
+// order as it was created
+func newOrderResponse(order *Orders) (*OrderResponses, error) {
+	body, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponses{Order_Id: order.ID, Status: http.StatusOK, Body: body}, nil
+}
+
+// createOrder runs in one unit of work, since stock is decremented book by
+// book and nothing may be committed unless the whole order went through
+func (u *Usecase) createOrder(ctx context.Context, request *CreateOrderRequest, count uint8) (*OrderResponses, error) {
+	funcName := "usecase.createOrder"
+
+	var response *OrderResponses
+	err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		_, err := u.Repo.GetOrderByIdempotencyKey(ctx, tx, request.Created_By, request.X_Idempotency_Key)
+		if err == nil {
+			return ErrIdempotencyKeyExists
+		}
+		if !errors.Is(err, ErrOrderNotFound) {
+			return err
+		}
+
+		used, err := u.Repo.IdempotencyKeyUsed(ctx, tx, request.X_Idempotency_Key)
+		if err != nil {
+			return err
+		}
+		if used {
+			u.Log.Warn(ctx, "invalid request body to create order: idempotency key of another client", "func_name", funcName)
+			return ErrIdempotencyKeyOwner
+		}
+
+		created, err := u.Repo.CreateOrder(ctx, tx, &Orders{
+			Created_By:        request.Created_By,
+			X_Idempotency_Key: request.X_Idempotency_Key,
+			Status:            StatusProcessed,
+			Count:             count,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = u.Repo.CreateStatusHistory(ctx, tx, &OrderStatusHistories{
+			Order_Id:  created.ID,
+			To_Status: StatusProcessed,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, book := range request.Books {
+			err = u.Repo.DecrementStock(ctx, tx, book.Book_Id, book.Quantity)
+			if err != nil {
+				u.Log.Warn(ctx, "failed request body to create order: decrement stock", "error", err, "func_name", funcName)
+				return err
+			}
+
+			err = u.Repo.CreateOrderBook(ctx, tx, created.ID, &OrderBooks{Book_Id: book.Book_Id, Quantity: book.Quantity})
+			if err != nil {
+				return err
+			}
+		}
+
+		order, err := u.Repo.GetOrderById(ctx, tx, created.ID)
+		if err != nil {
+			return err
+		}
+
+		response, err = newOrderResponse(order)
+		if err != nil {
+			return err
+		}
+		return u.Repo.CreateOrderResponse(ctx, tx, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// TransitionOrder moves the order to the requested status when the state
+// machine allows it, recording the change and restoring stock on cancel
+func (u *Usecase) TransitionOrder(ctx context.Context, request *TransitionOrderRequest) (*Orders, error) {
+	funcName := "usecase.TransitionOrder"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to transition order", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+
+	var order *Orders
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		status, err := u.Repo.LockOrderStatus(ctx, tx, request.ID)
+		if err != nil {
+			return err
+		}
+
+		if !CanTransition(status, request.Status) {
+			u.Log.Warn(ctx, "invalid request body to transition order", "from", status, "to", request.Status, "func_name", funcName)
+			return fmt.Errorf("%w: %s to %s", ErrIllegalTransition, status, request.Status)
+		}
+
+		err = u.Repo.UpdateOrderStatus(ctx, tx, request.ID, request.Status)
+		if err != nil {
+			return err
+		}
+
+		if request.Status == StatusCancelled {
+			err = u.Repo.RestoreStock(ctx, tx, request.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = u.Repo.CreateStatusHistory(ctx, tx, &OrderStatusHistories{
+			Order_Id:    request.ID,
+			From_Status: &status,
+			To_Status:   request.Status,
+			Changed_By:  nullableString(request.Changed_By),
+			Note:        nullableString(request.Note),
+		})
+		if err != nil {
+			return err
+		}
+
+		order, err = u.Repo.GetOrderById(ctx, tx, request.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// GetStatusHistories loads the transitions of the order, not found when
+// claims may not read it.
+func (u *Usecase) GetStatusHistories(ctx context.Context, claims auth.Claims, orderId uint64) ([]OrderStatusHistories, error) {
+	funcName := "usecase.GetStatusHistories"
+
+	var histories []OrderStatusHistories
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) error {
+		_, err := u.getReadableOrder(ctx, tx, claims, orderId)
+		if err != nil {
+			return err
+		}
+
+		histories, err = u.Repo.GetStatusHistories(ctx, tx, orderId)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get status histories", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// getReadableOrder loads the order when claims hold orders:read, or
+// orders:read-own and placed it. The orders of the others are not found
+// rather than forbidden, so their ids tell nothing.
+func (u *Usecase) getReadableOrder(ctx context.Context, tx store.Tx, claims auth.Claims, orderId uint64) (*Orders, error) {
+	order, err := u.Repo.GetOrderById(ctx, tx, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if u.Policy.Allows(claims, auth.OrdersRead) {
+		return order, nil
+	}
+	if u.Policy.Allows(claims, auth.OrdersReadOwn) && claims.Subject != "" && order.Created_By == claims.Subject {
+		return order, nil
+	}
+
+	return nil, ErrOrderNotFound
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+// replayOrder loads the response to the create of the order already created
+// with the request idempotency key, refusing it when the books differ from
+// the original request
+func (u *Usecase) replayOrder(ctx context.Context, request *CreateOrderRequest) (*OrderResponses, error) {
+	funcName := "usecase.replayOrder"
+
+	var order *Orders
+	var response *OrderResponses
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		order, err = u.Repo.GetOrderByIdempotencyKey(ctx, tx, request.Created_By, request.X_Idempotency_Key)
+		if err != nil {
+			return err
+		}
+
+		response, err = u.Repo.GetOrderResponse(ctx, tx, order.ID)
+		if errors.Is(err, ErrOrderResponseNotFound) {
+			// orders created before the responses were stored replay their
+			// current state
+			response, err = newOrderResponse(order)
+		}
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to replay order", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	if !sameBooks(order.Books, request.Books) {
+		u.Log.Warn(ctx, "invalid request body to replay order: payload mismatch", "order_id", order.ID, "func_name", funcName)
+		return nil, ErrIdempotencyKeyPayload
+	}
+
+	u.Log.Info(ctx, "replay order for idempotency key", "order_id", order.ID, "func_name", funcName)
+	return response, nil
+}
+
+func sameBooks(orderBooks []OrderBooks, requestBooks []CreateOrderBookRequest) bool {
+	if len(orderBooks) != len(requestBooks) {
+		return false
+	}
+
+	quantities := make(map[uint32]uint8, len(orderBooks))
+	for _, book := range orderBooks {
+		quantities[book.Book_Id] = book.Quantity
+	}
+
+	for _, book := range requestBooks {
+		if quantity, ok := quantities[book.Book_Id]; !ok || quantity != book.Quantity {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,89 @@
+package orders
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestSameBooks(t *testing.T) {
+	orderBooks := []OrderBooks{
+		{Book_Id: 1, Quantity: 2},
+		{Book_Id: 2, Quantity: 1},
+	}
+
+	testCases := []struct {
+		name     string
+		books    []CreateOrderBookRequest
+		expected bool
+	}{
+		{
+			name:     "same books in another order",
+			books:    []CreateOrderBookRequest{{Book_Id: 2, Quantity: 1}, {Book_Id: 1, Quantity: 2}},
+			expected: true,
+		},
+		{
+			name:     "different quantity",
+			books:    []CreateOrderBookRequest{{Book_Id: 1, Quantity: 1}, {Book_Id: 2, Quantity: 1}},
+			expected: false,
+		},
+		{
+			name:     "missing book",
+			books:    []CreateOrderBookRequest{{Book_Id: 1, Quantity: 2}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sameBooks(orderBooks, tc.books); got != tc.expected {
+				t.Fatalf("invalid same books: got %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCreateOrderRequest_validate(t *testing.T) {
+	validate := validator.New()
+
+	testCases := []struct {
+		name    string
+		request CreateOrderRequest
+		valid   bool
+	}{
+		{
+			name:    "valid",
+			request: CreateOrderRequest{X_Idempotency_Key: "key-1", Books: []CreateOrderBookRequest{{Book_Id: 1, Quantity: 2}}},
+			valid:   true,
+		},
+		{
+			name:    "missing idempotency key",
+			request: CreateOrderRequest{Books: []CreateOrderBookRequest{{Book_Id: 1, Quantity: 2}}},
+			valid:   false,
+		},
+		{
+			name:    "no books",
+			request: CreateOrderRequest{X_Idempotency_Key: "key-1"},
+			valid:   false,
+		},
+		{
+			name:    "duplicate book",
+			request: CreateOrderRequest{X_Idempotency_Key: "key-1", Books: []CreateOrderBookRequest{{Book_Id: 1, Quantity: 1}, {Book_Id: 1, Quantity: 1}}},
+			valid:   false,
+		},
+		{
+			name:    "quantity over max",
+			request: CreateOrderRequest{X_Idempotency_Key: "key-1", Books: []CreateOrderBookRequest{{Book_Id: 1, Quantity: 16}}},
+			valid:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validate.Struct(&tc.request)
+			if (err == nil) != tc.valid {
+				t.Fatalf("invalid request validation: got %v, want valid=%v", err, tc.valid)
+			}
+		})
+	}
+}
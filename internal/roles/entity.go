@@ -0,0 +1,10 @@
+package roles
+
+// Data models and structs specific to role functionality
+
+// Roles represents a role with the names of the permissions it grants
+type Roles struct {
+	ID          uint8
+	Name        string
+	Permissions []string
+}
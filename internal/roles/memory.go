@@ -0,0 +1,40 @@
+package roles
+
+import (
+	"context"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for role data
+
+// MemoryTable is the in-memory table of roles, named after the migration.
+// Its rows hold their permissions instead of the role_has_permission table
+const MemoryTable = "role"
+
+// CreateMemoryTables adds the role table to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, nil)
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetRoles(ctx context.Context, tx store.Tx) ([]Roles, error) {
+	roles, err := memory.Rows[Roles](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetRoles")
+		return nil, err
+	}
+
+	return roles, nil
+}
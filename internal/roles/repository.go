@@ -0,0 +1,78 @@
+package roles
+
+import (
+	"context"
+	"database/sql"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
+)
+
+// Database access methods for role data
+
+// Repository is the role data access used by the usecase
+type Repository interface {
+	GetRoles(ctx context.Context, tx store.Tx) ([]Roles, error)
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
+	DB  *sql.DB
+	Log *logger.Logger
+}
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "role", Columns: []string{"id", "name"}},
+	{Name: "permission", Columns: []string{"id", "name"}},
+	{Name: "role_has_permission", Columns: []string{"role_id", "permission_id"}},
+}
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// GetRoles returns every role with its permissions, roles without any
+// included
+func (r MySQLRepository) GetRoles(ctx context.Context, tx store.Tx) ([]Roles, error) {
+	funcName := "repository.GetRoles"
+	query := "SELECT r.id, r.name, p.name FROM role r " +
+		"LEFT JOIN role_has_permission rp ON rp.role_id = r.id " +
+		"LEFT JOIN permission p ON p.id = rp.permission_id " +
+		"ORDER BY r.id, p.name"
+
+	rows, err := store.SQL(tx).QueryContext(ctx, query)
+	if err != nil {
+		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []Roles
+	for rows.Next() {
+		var role Roles
+		var permission sql.NullString
+		if err := rows.Scan(&role.ID, &role.Name, &permission); err != nil {
+			r.Log.Error(ctx, "get scan into get roles with error", "error", err, "func_name", funcName)
+			return nil, err
+		}
+
+		if len(roles) == 0 || roles[len(roles)-1].ID != role.ID {
+			roles = append(roles, role)
+		}
+		if permission.Valid {
+			last := &roles[len(roles)-1]
+			last.Permissions = append(last.Permissions, permission.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		r.Log.Error(ctx, "get rows err with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,51 @@
+package roles
+
+import (
+	"context"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+)
+
+// Core business logic for role operations
+
+type Usecase struct {
+	Repo Repository
+	Tx   store.Beginner
+	Log  *logger.Logger
+}
+
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger) Usecase {
+	return Usecase{
+		Repo: repo,
+		Tx:   tx,
+		Log:  logger,
+	}
+}
+
+// GetPolicy returns the permissions every role grants, for the routes to
+// authorize requests with
+func (u *Usecase) GetPolicy(ctx context.Context) (auth.Policy, error) {
+	funcName := "usecase.GetPolicy"
+
+	var roles []Roles
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		roles, err = u.Repo.GetRoles(ctx, tx)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed to get roles", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	policy := make(auth.Policy, len(roles))
+	for _, role := range roles {
+		permissions := make([]auth.Permission, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, auth.Permission(permission))
+		}
+		policy[role.Name] = permissions
+	}
+
+	return policy, nil
+}
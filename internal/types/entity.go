@@ -0,0 +1,38 @@
+package types
+
+import (
+	"time"
+	"toko-buku-api/pkg/query"
+)
+
+// Data models and structs specific to book type (genre) functionality
+
+type Types struct {
+	ID         uint16
+	Updated_At *time.Time
+	Type       string
+}
+
+// QuerySpec lists the fields to paginate, sort and filter types by
+var QuerySpec = query.Spec{
+	Columns: map[string]string{
+		"id":         "id",
+		"updated_at": "updated_at",
+		"type":       "`type`",
+	},
+	Sortable: []string{"type"},
+	Key:      "id",
+	Types: map[string]query.Type{
+		"id":   query.TypeNumber,
+		"type": query.TypeString,
+	},
+}
+
+type CreateTypeRequest struct {
+	Type string `validate:"required,min=3,max=50" json:"type"`
+}
+
+type UpdateTypeRequest struct {
+	ID   uint16 `json:"id"`
+	Type string `validate:"omitempty,min=3,max=50" json:"type"`
+}
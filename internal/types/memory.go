@@ -0,0 +1,130 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for book type data
+
+// MemoryTable is the in-memory table of book types, named after the migration
+const MemoryTable = "type"
+
+// bookTable is the in-memory table of books, which references types
+const bookTable = "book"
+
+// CreateMemoryTables adds the type table to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, nil)
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetTypes(ctx context.Context, tx store.Tx, options *query.Options) ([]Types, *query.Page, error) {
+	types, err := memory.Rows[Types](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetTypes")
+		return nil, nil, err
+	}
+
+	return query.Apply(options, types, typeValue)
+}
+
+func (r MemoryRepository) GetTypeById(ctx context.Context, tx store.Tx, typeId uint16) (*Types, error) {
+	funcName := "memory.GetTypeById"
+
+	bookType, ok, err := memory.Get[Types](memory.Of(tx), MemoryTable, uint64(typeId))
+	if err != nil {
+		r.Log.Error(ctx, "get row with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(typeBaseError, typeId, err)
+	}
+	if !ok {
+		r.Log.Warn(ctx, fmt.Sprintf(typeNotFoundError, typeId), "func_name", funcName)
+		return nil, ErrTypeNotFound
+	}
+
+	return &bookType, nil
+}
+
+// GetTypeByName looks the type up case-insensitively, or returns ErrTypeNotFound
+func (r MemoryRepository) GetTypeByName(ctx context.Context, tx store.Tx, name string) (*Types, error) {
+	types, err := memory.Rows[Types](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetTypeByName")
+		return nil, err
+	}
+
+	for _, bookType := range types {
+		if strings.EqualFold(bookType.Type, name) {
+			return &bookType, nil
+		}
+	}
+
+	return nil, ErrTypeNotFound
+}
+
+// CountBooksByType counts every book row referencing the type, soft deleted
+// ones included, since the foreign key still restricts on them
+func (r MemoryRepository) CountBooksByType(ctx context.Context, tx store.Tx, typeId uint16) (int, error) {
+	count, err := memory.Of(tx).CountReferences(MemoryTable, uint64(typeId), bookTable)
+	if err != nil {
+		r.Log.Error(ctx, "count references with count books error", "error", err, "func_name", "memory.CountBooksByType")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r MemoryRepository) CreateType(ctx context.Context, tx store.Tx, bookType *Types) (*Types, error) {
+	updatedAt := time.Now()
+	bookType.Updated_At = &updatedAt
+
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *bookType
+		created.ID = uint16(id)
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create type error", "error", err, "func_name", "memory.CreateType")
+		return nil, err
+	}
+
+	bookType.ID = uint16(id)
+	return bookType, nil
+}
+
+func (r MemoryRepository) UpdateType(ctx context.Context, tx store.Tx, bookType *Types) (*Types, error) {
+	updatedAt := time.Now()
+	bookType.Updated_At = &updatedAt
+
+	if err := memory.Of(tx).Put(MemoryTable, uint64(bookType.ID), *bookType); err != nil {
+		r.Log.Error(ctx, "put row with update type error", "error", err, "func_name", "memory.UpdateType")
+		return nil, err
+	}
+
+	return bookType, nil
+}
+
+func (r MemoryRepository) DeleteType(ctx context.Context, tx store.Tx, bookType *Types) error {
+	if err := memory.Of(tx).Delete(MemoryTable, uint64(bookType.ID)); err != nil {
+		r.Log.Error(ctx, "delete row with delete type error", "error", err, "func_name", "memory.DeleteType")
+		return err
+	}
+
+	return nil
+}
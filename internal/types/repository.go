@@ -0,0 +1,196 @@
+package types
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
+)
+
+// Database access methods for book type data
+
+// Repository is the book type data access used by the usecase
+type Repository interface {
+	GetTypes(ctx context.Context, tx store.Tx, options *query.Options) ([]Types, *query.Page, error)
+	GetTypeById(ctx context.Context, tx store.Tx, typeId uint16) (*Types, error)
+	GetTypeByName(ctx context.Context, tx store.Tx, name string) (*Types, error)
+	CountBooksByType(ctx context.Context, tx store.Tx, typeId uint16) (int, error)
+	CreateType(ctx context.Context, tx store.Tx, bookType *Types) (*Types, error)
+	UpdateType(ctx context.Context, tx store.Tx, bookType *Types) (*Types, error)
+	DeleteType(ctx context.Context, tx store.Tx, bookType *Types) error
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
+	DB  *sql.DB
+	Log *logger.Logger
+}
+
+const (
+	typeBaseError     = "type %d: %v"
+	typeNotFoundError = "type %d: not found"
+)
+
+var (
+	ErrTypeNotFound = errs.NotFound("type not found")
+	ErrTypeConflict = errs.Conflict("type already exists")
+	ErrTypeInUse    = errs.Conflict("type is still referenced by books")
+)
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "type", Columns: []string{"id", "updated_at", "type"}},
+	{Name: "book", Columns: []string{"type_id"}},
+}
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+func (r MySQLRepository) GetTypes(ctx context.Context, tx store.Tx, options *query.Options) ([]Types, *query.Page, error) {
+	var funcName = "repository.GetTypes"
+	var types []Types
+	selectQuery, args := options.Select("SELECT id, updated_at, `type` FROM `type`", false)
+
+	rows, err := store.SQL(tx).QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		r.Log.Error(ctx, "get query context with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookType Types
+		if err := rows.Scan(&bookType.ID, &bookType.Updated_At, &bookType.Type); err != nil {
+			r.Log.Error(ctx, "get scan into get types with error", "error", err, "func_name", funcName)
+			return nil, nil, err
+		}
+
+		types = append(types, bookType)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.Log.Error(ctx, "get rows err with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	var total int
+	countQuery, countArgs := options.Count("SELECT COUNT(*) FROM `type`", false)
+	if err := store.SQL(tx).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		r.Log.Error(ctx, "get count types with error", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	return query.Paginate(options, types, total, typeValue)
+}
+
+// typeValue returns the value of a QuerySpec field for cursors
+func typeValue(bookType Types, field string) any {
+	switch field {
+	case "updated_at":
+		return bookType.Updated_At
+	case "type":
+		return bookType.Type
+	default:
+		return bookType.ID
+	}
+}
+
+func (r MySQLRepository) GetTypeById(ctx context.Context, tx store.Tx, typeId uint16) (*Types, error) {
+	funcName := "repository.GetTypeById"
+	query := "SELECT id, updated_at, `type` FROM `type` WHERE id = ?"
+
+	var bookType Types
+	err := store.SQL(tx).QueryRowContext(ctx, query, typeId).Scan(&bookType.ID, &bookType.Updated_At, &bookType.Type)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(typeNotFoundError, typeId), "func_name", funcName)
+			return nil, ErrTypeNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get type by id with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(typeBaseError, typeId, err)
+	}
+
+	return &bookType, nil
+}
+
+// GetTypeByName looks the type up case-insensitively, or returns ErrTypeNotFound
+func (r MySQLRepository) GetTypeByName(ctx context.Context, tx store.Tx, name string) (*Types, error) {
+	funcName := "repository.GetTypeByName"
+	query := "SELECT id, updated_at, `type` FROM `type` WHERE LOWER(`type`) = LOWER(?)"
+
+	var bookType Types
+	err := store.SQL(tx).QueryRowContext(ctx, query, name).Scan(&bookType.ID, &bookType.Updated_At, &bookType.Type)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTypeNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get type by name with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return &bookType, nil
+}
+
+// CountBooksByType counts every book row referencing the type, soft deleted
+// ones included, since the foreign key still restricts on them
+func (r MySQLRepository) CountBooksByType(ctx context.Context, tx store.Tx, typeId uint16) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM book WHERE type_id = ?"
+	err := store.SQL(tx).QueryRowContext(ctx, query, typeId).Scan(&count)
+	if err != nil {
+		r.Log.Error(ctx, "get query row context with count books error", "error", err, "func_name", "repository.CountBooksByType")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r MySQLRepository) CreateType(ctx context.Context, tx store.Tx, bookType *Types) (*Types, error) {
+	funcName := "repository.CreateType"
+
+	query := "INSERT INTO `type`(`type`) VALUES (?)"
+	result, err := store.SQL(tx).ExecContext(ctx, query, bookType.Type)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create type error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	typeId, err := result.LastInsertId()
+	if err != nil {
+		r.Log.Error(ctx, "get result last insert id with create type error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+	bookType.ID = uint16(typeId)
+	return bookType, nil
+}
+
+func (r MySQLRepository) UpdateType(ctx context.Context, tx store.Tx, bookType *Types) (*Types, error) {
+	query := "UPDATE `type` SET `type` = ? WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, bookType.Type, bookType.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with update type error", "error", err, "func_name", "repository.UpdateType")
+		return nil, err
+	}
+
+	return bookType, nil
+}
+
+func (r MySQLRepository) DeleteType(ctx context.Context, tx store.Tx, bookType *Types) error {
+	query := "DELETE FROM `type` WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, bookType.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with delete type error", "error", err, "func_name", "repository.DeleteType")
+		return err
+	}
+
+	return nil
+}
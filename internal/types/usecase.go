@@ -0,0 +1,162 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/query"
+	"toko-buku-api/pkg/store"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Core business logic for book type operations
+
+type Usecase struct {
+	Repo     Repository
+	Tx       store.Beginner
+	Log      *logger.Logger
+	Validate *validator.Validate
+}
+
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate) Usecase {
+	return Usecase{
+		Repo:     repo,
+		Tx:       tx,
+		Log:      logger,
+		Validate: validate,
+	}
+}
+
+func (u *Usecase) GetTypes(ctx context.Context, options *query.Options) ([]Types, *query.Page, error) {
+	funcName := "usecase.GetTypes"
+
+	var types []Types
+	var page *query.Page
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		types, page, err = u.Repo.GetTypes(ctx, tx, options)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get types", "error", err, "func_name", funcName)
+		return nil, nil, err
+	}
+
+	return types, page, nil
+}
+
+func (u *Usecase) GetTypeById(ctx context.Context, typeId uint16) (*Types, error) {
+	funcName := "usecase.GetTypeById"
+
+	var bookType *Types
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		bookType, err = u.Repo.GetTypeById(ctx, tx, typeId)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to get type by id: not found by id", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return bookType, nil
+}
+
+func (u *Usecase) CreateType(ctx context.Context, request *CreateTypeRequest) (*Types, error) {
+	funcName := "usecase.CreateType"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to create type", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+
+	var createdType *Types
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		_, err := u.Repo.GetTypeByName(ctx, tx, request.Type)
+		if err == nil {
+			u.Log.Warn(ctx, "invalid request body to create type: type already exists", "type", request.Type, "func_name", funcName)
+			return ErrTypeConflict
+		}
+		if !errors.Is(err, ErrTypeNotFound) {
+			return err
+		}
+
+		createdType, err = u.Repo.CreateType(ctx, tx, &Types{Type: request.Type})
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to create type", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return createdType, nil
+}
+
+func (u *Usecase) UpdateType(ctx context.Context, request *UpdateTypeRequest) (*Types, error) {
+	funcName := "usecase.UpdateType"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to update type", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+
+	var updatedType *Types
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		oldType, err := u.Repo.GetTypeById(ctx, tx, request.ID)
+		if err != nil {
+			u.Log.Warn(ctx, "failed request body to update type: repo GetTypeById", "error", err, "func_name", funcName)
+			return err
+		}
+
+		if request.Type != "" {
+			sameName, err := u.Repo.GetTypeByName(ctx, tx, request.Type)
+			if err == nil && sameName.ID != oldType.ID {
+				u.Log.Warn(ctx, "invalid request body to update type: type already exists", "type", request.Type, "func_name", funcName)
+				return ErrTypeConflict
+			}
+			if err != nil && !errors.Is(err, ErrTypeNotFound) {
+				return err
+			}
+			oldType.Type = request.Type
+		}
+
+		updatedType, err = u.Repo.UpdateType(ctx, tx, oldType)
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to update type", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return updatedType, nil
+}
+
+func (u *Usecase) DeleteType(ctx context.Context, typeId uint16) error {
+	funcName := "usecase.DeleteType"
+
+	err := store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		bookType, err := u.Repo.GetTypeById(ctx, tx, typeId)
+		if err != nil {
+			return err
+		}
+
+		count, err := u.Repo.CountBooksByType(ctx, tx, typeId)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			u.Log.Warn(ctx, "failed request body to delete type: still referenced by books", "books", count, "func_name", funcName)
+			return ErrTypeInUse
+		}
+
+		return u.Repo.DeleteType(ctx, tx, bookType)
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to delete type", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package users
+
+import "time"
+
+// Data models and structs specific to user account functionality
+
+type Users struct {
+	ID            uint32     `json:"id"`
+	Created_At    time.Time  `json:"created_at"`
+	Updated_At    time.Time  `json:"updated_at"`
+	Email         string     `json:"email"`
+	Password_Hash string     `json:"-"`
+	Role          string     `json:"role"`
+	Failed_Logins uint8      `json:"-"`
+	Locked_Until  *time.Time `json:"-"`
+}
+
+// PasswordResets represents a password reset token, stored as the sha256
+// hash of the token sent to the user
+type PasswordResets struct {
+	ID         uint32
+	Created_At time.Time
+	User_Id    uint32
+	Token_Hash string
+	Expires_At time.Time
+	Used_At    *time.Time
+}
+
+type RegisterUserRequest struct {
+	Email    string `validate:"required,email,max=254" json:"email"`
+	Password string `validate:"required,min=8,max=72" json:"password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `validate:"required,email,max=254" json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `validate:"required" json:"token"`
+	Password string `validate:"required,min=8,max=72" json:"password"`
+}
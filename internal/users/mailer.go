@@ -0,0 +1,35 @@
+package users
+
+import (
+	"context"
+	"time"
+	"toko-buku-api/pkg/logger"
+)
+
+// Mailer delivers password reset tokens to the users
+type Mailer interface {
+	SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error
+}
+
+// LogMailer logs the password reset requests instead of sending them, for
+// running without a mail server. The tokens are never logged: whoever reads
+// the log could reset the passwords
+type LogMailer struct {
+	Log *logger.Logger
+}
+
+func (m LogMailer) SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error {
+	m.Log.Info(ctx, "password reset requested, no mail server to send it", "email", email, "expires_at", expiresAt)
+	return nil
+}
+
+// DevMailer writes the password reset tokens to the log, for development
+// only, where the log is the only way to get them
+type DevMailer struct {
+	Log *logger.Logger
+}
+
+func (m DevMailer) SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error {
+	m.Log.Warn(ctx, "password reset requested, token logged for development", "email", email, "token", token, "expires_at", expiresAt)
+	return nil
+}
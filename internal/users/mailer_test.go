@@ -0,0 +1,24 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"toko-buku-api/pkg/logger"
+)
+
+func TestLogMailer_hidesToken(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := LogMailer{Log: logger.New(&buf, logger.LevelDebug, "TEST", nil)}
+
+	token := "reset-token-0123456789"
+	if err := mailer.SendPasswordReset(context.Background(), "budi@example.com", token, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(buf.String(), token) || !strings.Contains(buf.String(), "budi@example.com") {
+		t.Fatalf("invalid log: got %s", buf.String())
+	}
+}
@@ -0,0 +1,152 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+)
+
+// In-memory data access methods for user account data
+
+// In-memory tables of user accounts, named after the migrations
+const (
+	MemoryTable              = "user"
+	MemoryPasswordResetTable = "password_reset"
+)
+
+// CreateMemoryTables adds the user tables to the in-memory database
+func CreateMemoryTables(db *memory.DB) {
+	db.CreateTable(MemoryTable, nil)
+	db.CreateTable(MemoryPasswordResetTable, func(row any) map[string]uint64 {
+		return map[string]uint64{MemoryTable: uint64(row.(PasswordResets).User_Id)}
+	})
+}
+
+// MemoryRepository implements Repository on the in-memory database
+type MemoryRepository struct {
+	Log *logger.Logger
+}
+
+func NewMemoryRepository(logger *logger.Logger) MemoryRepository {
+	return MemoryRepository{
+		Log: logger,
+	}
+}
+
+func (r MemoryRepository) GetUserById(ctx context.Context, tx store.Tx, userId uint32) (*Users, error) {
+	funcName := "memory.GetUserById"
+
+	user, ok, err := memory.Get[Users](memory.Of(tx), MemoryTable, uint64(userId))
+	if err != nil {
+		r.Log.Error(ctx, "get row with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(userBaseError, userId, err)
+	}
+	if !ok {
+		r.Log.Warn(ctx, fmt.Sprintf(userNotFoundError, userId), "func_name", funcName)
+		return nil, ErrUserNotFound
+	}
+
+	return &user, nil
+}
+
+// LockUser returns the user, the transaction already holds the database
+// exclusively
+func (r MemoryRepository) LockUser(ctx context.Context, tx store.Tx, userId uint32) (*Users, error) {
+	return r.GetUserById(ctx, tx, userId)
+}
+
+func (r MemoryRepository) GetUserByEmail(ctx context.Context, tx store.Tx, email string) (*Users, error) {
+	users, err := memory.Rows[Users](memory.Of(tx), MemoryTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetUserByEmail")
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
+
+func (r MemoryRepository) CreateUser(ctx context.Context, tx store.Tx, user *Users) (*Users, error) {
+	now := time.Now()
+	user.Created_At, user.Updated_At = now, now
+	id, err := memory.Of(tx).Insert(MemoryTable, func(id uint64) any {
+		created := *user
+		created.ID = uint32(id)
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create user error", "error", err, "func_name", "memory.CreateUser")
+		return nil, err
+	}
+
+	user.ID = uint32(id)
+	return user, nil
+}
+
+func (r MemoryRepository) UpdateLogin(ctx context.Context, tx store.Tx, user *Users) error {
+	return r.put(ctx, tx, user, "memory.UpdateLogin")
+}
+
+func (r MemoryRepository) UpdatePassword(ctx context.Context, tx store.Tx, user *Users) error {
+	user.Failed_Logins = 0
+	user.Locked_Until = nil
+	return r.put(ctx, tx, user, "memory.UpdatePassword")
+}
+
+func (r MemoryRepository) put(ctx context.Context, tx store.Tx, user *Users, funcName string) error {
+	user.Updated_At = time.Now()
+	if err := memory.Of(tx).Put(MemoryTable, uint64(user.ID), *user); err != nil {
+		r.Log.Error(ctx, "put row with update user error", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
+
+func (r MemoryRepository) CreatePasswordReset(ctx context.Context, tx store.Tx, reset *PasswordResets) error {
+	reset.Created_At = time.Now()
+	_, err := memory.Of(tx).Insert(MemoryPasswordResetTable, func(id uint64) any {
+		created := *reset
+		created.ID = uint32(id)
+		return created
+	})
+	if err != nil {
+		r.Log.Error(ctx, "insert row with create password reset error", "error", err, "func_name", "memory.CreatePasswordReset")
+		return err
+	}
+
+	return nil
+}
+
+func (r MemoryRepository) GetPasswordReset(ctx context.Context, tx store.Tx, tokenHash string) (*PasswordResets, error) {
+	resets, err := memory.Rows[PasswordResets](memory.Of(tx), MemoryPasswordResetTable)
+	if err != nil {
+		r.Log.Error(ctx, "get rows with error", "error", err, "func_name", "memory.GetPasswordReset")
+		return nil, err
+	}
+
+	for _, reset := range resets {
+		if reset.Token_Hash == tokenHash {
+			return &reset, nil
+		}
+	}
+
+	return nil, ErrInvalidResetToken
+}
+
+func (r MemoryRepository) UsePasswordReset(ctx context.Context, tx store.Tx, reset *PasswordResets) error {
+	if err := memory.Of(tx).Put(MemoryPasswordResetTable, uint64(reset.ID), *reset); err != nil {
+		r.Log.Error(ctx, "put row with use password reset error", "error", err, "func_name", "memory.UsePasswordReset")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/schema"
+	"toko-buku-api/pkg/store"
+)
+
+// Database access methods for user account data
+
+// Repository is the user account data access used by the usecase
+type Repository interface {
+	GetUserById(ctx context.Context, tx store.Tx, userId uint32) (*Users, error)
+	LockUser(ctx context.Context, tx store.Tx, userId uint32) (*Users, error)
+	GetUserByEmail(ctx context.Context, tx store.Tx, email string) (*Users, error)
+	CreateUser(ctx context.Context, tx store.Tx, user *Users) (*Users, error)
+	UpdateLogin(ctx context.Context, tx store.Tx, user *Users) error
+	UpdatePassword(ctx context.Context, tx store.Tx, user *Users) error
+	CreatePasswordReset(ctx context.Context, tx store.Tx, reset *PasswordResets) error
+	GetPasswordReset(ctx context.Context, tx store.Tx, tokenHash string) (*PasswordResets, error)
+	UsePasswordReset(ctx context.Context, tx store.Tx, reset *PasswordResets) error
+}
+
+// MySQLRepository implements Repository on MySQL
+type MySQLRepository struct {
+	DB  *sql.DB
+	Log *logger.Logger
+}
+
+const (
+	userBaseError     = "user %d: %v"
+	userNotFoundError = "user %d: not found"
+)
+
+var (
+	ErrUserNotFound      = errs.NotFound("user not found")
+	ErrEmailConflict     = errs.Conflict("email is already registered")
+	ErrAccountLocked     = errs.Unauthorized("account is locked, try again later")
+	ErrInvalidResetToken = errs.BadRequest("invalid or expired reset token")
+)
+
+// Schema lists the tables and columns MySQLRepository queries
+var Schema = []schema.Table{
+	{Name: "user", Columns: []string{"id", "created_at", "updated_at", "email", "password_hash", "role_id", "failed_logins", "locked_until"}},
+	{Name: "role", Columns: []string{"id", "name"}},
+	{Name: "password_reset", Columns: []string{"id", "created_at", "user_id", "token_hash", "expires_at", "used_at"}},
+}
+
+const selectUser = "SELECT u.id, u.created_at, u.updated_at, u.email, u.password_hash, r.name, u.failed_logins, u.locked_until " +
+	"FROM `user` u JOIN role r ON r.id = u.role_id"
+
+func NewMySQLRepository(db *sql.DB, logger *logger.Logger) MySQLRepository {
+	return MySQLRepository{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+func scanUser(row *sql.Row) (*Users, error) {
+	var user Users
+	err := row.Scan(&user.ID, &user.Created_At, &user.Updated_At, &user.Email, &user.Password_Hash, &user.Role, &user.Failed_Logins, &user.Locked_Until)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r MySQLRepository) GetUserById(ctx context.Context, tx store.Tx, userId uint32) (*Users, error) {
+	funcName := "repository.GetUserById"
+
+	user, err := scanUser(store.SQL(tx).QueryRowContext(ctx, selectUser+" WHERE u.id = ?", userId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(userNotFoundError, userId), "func_name", funcName)
+			return nil, ErrUserNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get user by id with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(userBaseError, userId, err)
+	}
+
+	return user, nil
+}
+
+// LockUser locks the user row for the rest of the transaction and returns
+// it, so its failed logins are counted one request at a time
+func (r MySQLRepository) LockUser(ctx context.Context, tx store.Tx, userId uint32) (*Users, error) {
+	funcName := "repository.LockUser"
+
+	user, err := scanUser(store.SQL(tx).QueryRowContext(ctx, selectUser+" WHERE u.id = ? FOR UPDATE", userId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.Log.Warn(ctx, fmt.Sprintf(userNotFoundError, userId), "func_name", funcName)
+			return nil, ErrUserNotFound
+		}
+		r.Log.Error(ctx, "get scan row into lock user with error", "error", err, "func_name", funcName)
+		return nil, fmt.Errorf(userBaseError, userId, err)
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail looks the user up by its lowercased email, or returns
+// ErrUserNotFound
+func (r MySQLRepository) GetUserByEmail(ctx context.Context, tx store.Tx, email string) (*Users, error) {
+	funcName := "repository.GetUserByEmail"
+
+	user, err := scanUser(store.SQL(tx).QueryRowContext(ctx, selectUser+" WHERE u.email = ?", email))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		r.Log.Error(ctx, "get scan row into get user by email with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateUser inserts the user with the id of its role, looked up by name
+func (r MySQLRepository) CreateUser(ctx context.Context, tx store.Tx, user *Users) (*Users, error) {
+	funcName := "repository.CreateUser"
+
+	query := "INSERT INTO `user`(email, password_hash, role_id) SELECT ?, ?, id FROM role WHERE name = ?"
+	result, err := store.SQL(tx).ExecContext(ctx, query, user.Email, user.Password_Hash, user.Role)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create user error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	userId, err := result.LastInsertId()
+	if err != nil {
+		r.Log.Error(ctx, "get result last insert id with create user error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return r.GetUserById(ctx, tx, uint32(userId))
+}
+
+func (r MySQLRepository) UpdateLogin(ctx context.Context, tx store.Tx, user *Users) error {
+	query := "UPDATE `user` SET failed_logins = ?, locked_until = ? WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, user.Failed_Logins, user.Locked_Until, user.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with update login error", "error", err, "func_name", "repository.UpdateLogin")
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePassword stores the new password hash and unlocks the account
+func (r MySQLRepository) UpdatePassword(ctx context.Context, tx store.Tx, user *Users) error {
+	query := "UPDATE `user` SET password_hash = ?, failed_logins = 0, locked_until = NULL WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, user.Password_Hash, user.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with update password error", "error", err, "func_name", "repository.UpdatePassword")
+		return err
+	}
+
+	return nil
+}
+
+func (r MySQLRepository) CreatePasswordReset(ctx context.Context, tx store.Tx, reset *PasswordResets) error {
+	query := "INSERT INTO password_reset(user_id, token_hash, expires_at) VALUES (?, ?, ?)"
+	_, err := store.SQL(tx).ExecContext(ctx, query, reset.User_Id, reset.Token_Hash, reset.Expires_At)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with create password reset error", "error", err, "func_name", "repository.CreatePasswordReset")
+		return err
+	}
+
+	return nil
+}
+
+// GetPasswordReset returns the reset of the token hash, or
+// ErrInvalidResetToken
+func (r MySQLRepository) GetPasswordReset(ctx context.Context, tx store.Tx, tokenHash string) (*PasswordResets, error) {
+	funcName := "repository.GetPasswordReset"
+	query := "SELECT id, created_at, user_id, token_hash, expires_at, used_at FROM password_reset WHERE token_hash = ?"
+
+	var reset PasswordResets
+	err := store.SQL(tx).QueryRowContext(ctx, query, tokenHash).Scan(&reset.ID, &reset.Created_At, &reset.User_Id, &reset.Token_Hash, &reset.Expires_At, &reset.Used_At)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidResetToken
+		}
+		r.Log.Error(ctx, "get scan row into get password reset with error", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+func (r MySQLRepository) UsePasswordReset(ctx context.Context, tx store.Tx, reset *PasswordResets) error {
+	query := "UPDATE password_reset SET used_at = ? WHERE id = ?"
+	_, err := store.SQL(tx).ExecContext(ctx, query, reset.Used_At, reset.ID)
+	if err != nil {
+		r.Log.Error(ctx, "get exec context with use password reset error", "error", err, "func_name", "repository.UsePasswordReset")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Core business logic for user account operations
+
+const (
+	// MaxFailedLogins is the number of wrong passwords in a row locking an
+	// account for LockoutDuration
+	MaxFailedLogins = 5
+	LockoutDuration = 15 * time.Minute
+
+	// ResetTokenTTL is how long a password reset token is valid
+	ResetTokenTTL = time.Hour
+)
+
+// dummyHash is compared for unknown emails, so they take as long as wrong
+// passwords
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+type Usecase struct {
+	Repo     Repository
+	Tx       store.Beginner
+	Log      *logger.Logger
+	Validate *validator.Validate
+	Mailer   Mailer
+}
+
+func NewUsecase(repo Repository, tx store.Beginner, logger *logger.Logger, validate *validator.Validate, mailer Mailer) Usecase {
+	return Usecase{
+		Repo:     repo,
+		Tx:       tx,
+		Log:      logger,
+		Validate: validate,
+		Mailer:   mailer,
+	}
+}
+
+// normalizeEmail lowercases the email, the form it is stored and looked up in
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// hashPassword returns the bcrypt hash of the password
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", errs.Validation("password is longer than 72 bytes")
+	}
+	if err != nil {
+		return "", errs.Internal(err)
+	}
+
+	return string(hash), nil
+}
+
+// hashToken returns the hex sha256 of a reset token, the form it is stored in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisterUser creates a customer account for an email not registered yet
+func (u *Usecase) RegisterUser(ctx context.Context, request *RegisterUserRequest) (*Users, error) {
+	funcName := "usecase.RegisterUser"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to register user", "error", err, "func_name", funcName)
+		return nil, errs.Invalid(err)
+	}
+
+	passwordHash, err := hashPassword(request.Password)
+	if err != nil {
+		return nil, err
+	}
+	email := normalizeEmail(request.Email)
+
+	var createdUser *Users
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		_, err := u.Repo.GetUserByEmail(ctx, tx, email)
+		if err == nil {
+			u.Log.Warn(ctx, "invalid request body to register user: email already registered", "func_name", funcName)
+			return ErrEmailConflict
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return err
+		}
+
+		createdUser, err = u.Repo.CreateUser(ctx, tx, &Users{Email: email, Password_Hash: passwordHash, Role: auth.RoleCustomer})
+		return err
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to register user", "error", err, "func_name", funcName)
+		return nil, err
+	}
+
+	return createdUser, nil
+}
+
+// Authenticate checks the password of the user with the email, returning
+// its claims. MaxFailedLogins wrong passwords in a row lock the account for
+// LockoutDuration, during which even the right password is refused
+func (u *Usecase) Authenticate(ctx context.Context, email, password string) (auth.Claims, error) {
+	funcName := "usecase.Authenticate"
+	email = normalizeEmail(email)
+
+	var user *Users
+	err := store.WithTx(ctx, u.Tx, store.ReadOnly, func(tx store.Tx) (err error) {
+		user, err = u.Repo.GetUserByEmail(ctx, tx, email)
+		return err
+	})
+	if errors.Is(err, ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return auth.Claims{}, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	now := time.Now()
+	if user.Locked_Until != nil && now.Before(*user.Locked_Until) {
+		u.Log.Warn(ctx, "failed to authenticate locked user", "user_id", user.ID, "func_name", funcName)
+		return auth.Claims{}, ErrAccountLocked
+	}
+
+	passwordErr := bcrypt.CompareHashAndPassword([]byte(user.Password_Hash), []byte(password))
+
+	// the guesses racing the one locking the account are checked again with
+	// the row locked, and neither counted nor let in
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		user, err := u.Repo.LockUser(ctx, tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if user.Locked_Until != nil && now.Before(*user.Locked_Until) {
+			return ErrAccountLocked
+		}
+
+		if passwordErr == nil {
+			if user.Failed_Logins == 0 && user.Locked_Until == nil {
+				return nil
+			}
+			user.Failed_Logins, user.Locked_Until = 0, nil
+			return u.Repo.UpdateLogin(ctx, tx, user)
+		}
+
+		user.Failed_Logins++
+		if user.Failed_Logins >= MaxFailedLogins {
+			lockedUntil := now.Add(LockoutDuration)
+			user.Failed_Logins, user.Locked_Until = 0, &lockedUntil
+			u.Log.Warn(ctx, "lock user after failed logins", "user_id", user.ID, "locked_until", lockedUntil, "func_name", funcName)
+		}
+		return u.Repo.UpdateLogin(ctx, tx, user)
+	})
+	if errors.Is(err, ErrAccountLocked) {
+		u.Log.Warn(ctx, "failed to authenticate locked user", "user_id", user.ID, "func_name", funcName)
+		return auth.Claims{}, err
+	}
+	if err != nil {
+		u.Log.Warn(ctx, "failed to update user login", "error", err, "func_name", funcName)
+		return auth.Claims{}, err
+	}
+	if passwordErr != nil {
+		return auth.Claims{}, auth.ErrInvalidCredentials
+	}
+
+	return auth.Claims{Subject: strconv.FormatUint(uint64(user.ID), 10), Role: user.Role}, nil
+}
+
+// ForgotPassword sends a reset token valid for ResetTokenTTL to the user
+// with the email. Unknown emails succeed too, so they cannot be told apart
+func (u *Usecase) ForgotPassword(ctx context.Context, request *ForgotPasswordRequest) error {
+	funcName := "usecase.ForgotPassword"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to forgot password", "error", err, "func_name", funcName)
+		return errs.Invalid(err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return errs.Internal(err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+	expiresAt := time.Now().Add(ResetTokenTTL)
+
+	var user *Users
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) (err error) {
+		user, err = u.Repo.GetUserByEmail(ctx, tx, normalizeEmail(request.Email))
+		if err != nil {
+			return err
+		}
+
+		return u.Repo.CreatePasswordReset(ctx, tx, &PasswordResets{User_Id: user.ID, Token_Hash: hashToken(token), Expires_At: expiresAt})
+	})
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to forgot password", "error", err, "func_name", funcName)
+		return err
+	}
+
+	if err := u.Mailer.SendPasswordReset(ctx, user.Email, token, expiresAt); err != nil {
+		u.Log.Error(ctx, "failed to send password reset", "error", err, "func_name", funcName)
+		return errs.Internal(err)
+	}
+
+	return nil
+}
+
+// ResetPassword replaces the password of the user of an unused, unexpired
+// reset token, unlocking the account
+func (u *Usecase) ResetPassword(ctx context.Context, request *ResetPasswordRequest) error {
+	funcName := "usecase.ResetPassword"
+
+	err := u.Validate.Struct(request)
+	if err != nil {
+		u.Log.Warn(ctx, "invalid request body to reset password", "error", err, "func_name", funcName)
+		return errs.Invalid(err)
+	}
+
+	passwordHash, err := hashPassword(request.Password)
+	if err != nil {
+		return err
+	}
+
+	err = store.WithTx(ctx, u.Tx, nil, func(tx store.Tx) error {
+		reset, err := u.Repo.GetPasswordReset(ctx, tx, hashToken(request.Token))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if reset.Used_At != nil || !now.Before(reset.Expires_At) {
+			return ErrInvalidResetToken
+		}
+
+		user, err := u.Repo.GetUserById(ctx, tx, reset.User_Id)
+		if err != nil {
+			return err
+		}
+		user.Password_Hash = passwordHash
+		if err := u.Repo.UpdatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+
+		reset.Used_At = &now
+		return u.Repo.UsePasswordReset(ctx, tx, reset)
+	})
+	if err != nil {
+		u.Log.Warn(ctx, "failed request body to reset password", "error", err, "func_name", funcName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// fakeMailer keeps the last password reset token instead of sending it
+type fakeMailer struct {
+	token string
+}
+
+func (m *fakeMailer) SendPasswordReset(ctx context.Context, email, token string, expiresAt time.Time) error {
+	m.token = token
+	return nil
+}
+
+func newMemoryUsecase(t *testing.T) (*Usecase, *fakeMailer) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", nil)
+	db := memory.New()
+	CreateMemoryTables(db)
+
+	mailer := &fakeMailer{}
+	usecase := NewUsecase(NewMemoryRepository(log), db, log, validator.New(), mailer)
+	return &usecase, mailer
+}
+
+func TestUsecase_RegisterUser(t *testing.T) {
+	ctx := context.Background()
+	usecase, _ := newMemoryUsecase(t)
+
+	user, err := usecase.RegisterUser(ctx, &RegisterUserRequest{Email: "Budi@Example.com", Password: "rahasia!"})
+	if err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+	if user.Email != "budi@example.com" || user.Role != auth.RoleCustomer || user.Password_Hash == "rahasia!" {
+		t.Fatalf("invalid user: got %+v", user)
+	}
+
+	_, err = usecase.RegisterUser(ctx, &RegisterUserRequest{Email: "budi@EXAMPLE.com", Password: "rahasia!"})
+	if !errors.Is(err, ErrEmailConflict) {
+		t.Fatalf("invalid error of a registered email: got %v, want %v", err, ErrEmailConflict)
+	}
+
+	_, err = usecase.RegisterUser(ctx, &RegisterUserRequest{Email: "budi", Password: "short"})
+	if kind := errs.KindOf(err); kind != errs.KindValidation {
+		t.Fatalf("invalid kind of an invalid request: got %s, want %s", kind, errs.KindValidation)
+	}
+}
+
+func TestUsecase_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	usecase, _ := newMemoryUsecase(t)
+
+	user, err := usecase.RegisterUser(ctx, &RegisterUserRequest{Email: "budi@example.com", Password: "rahasia!"})
+	if err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+
+	claims, err := usecase.Authenticate(ctx, "BUDI@example.com", "rahasia!")
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	if claims.Subject != "1" || claims.Role != auth.RoleCustomer {
+		t.Fatalf("invalid claims: got %+v", claims)
+	}
+
+	if _, err := usecase.Authenticate(ctx, "siti@example.com", "rahasia!"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Fatalf("invalid error of an unknown email: got %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+
+	for i := 0; i < MaxFailedLogins; i++ {
+		if _, err := usecase.Authenticate(ctx, "budi@example.com", "salah"); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Fatalf("invalid error of wrong password %d: got %v, want %v", i+1, err, auth.ErrInvalidCredentials)
+		}
+	}
+
+	if _, err := usecase.Authenticate(ctx, "budi@example.com", "rahasia!"); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("invalid error of a locked account: got %v, want %v", err, ErrAccountLocked)
+	}
+
+	// unlock the account as if LockoutDuration went by
+	err = store.WithTx(ctx, usecase.Tx, nil, func(tx store.Tx) error {
+		locked, err := usecase.Repo.GetUserById(ctx, tx, user.ID)
+		if err != nil {
+			return err
+		}
+		past := time.Now().Add(-time.Second)
+		locked.Locked_Until = &past
+		return usecase.Repo.UpdateLogin(ctx, tx, locked)
+	})
+	if err != nil {
+		t.Fatalf("failed to expire the lockout: %v", err)
+	}
+
+	if _, err := usecase.Authenticate(ctx, "budi@example.com", "rahasia!"); err != nil {
+		t.Fatalf("failed to authenticate after the lockout: %v", err)
+	}
+}
+
+func TestUsecase_Authenticate_concurrent(t *testing.T) {
+	ctx := context.Background()
+	usecase, _ := newMemoryUsecase(t)
+
+	if _, err := usecase.RegisterUser(ctx, &RegisterUserRequest{Email: "budi@example.com", Password: "rahasia!"}); err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+
+	// the guesses all read the account unlocked before any is counted
+	const guesses = 4 * MaxFailedLogins
+	results := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := usecase.Authenticate(ctx, "budi@example.com", "salah")
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	invalid := 0
+	for err := range results {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			invalid++
+		case !errors.Is(err, ErrAccountLocked):
+			t.Fatalf("invalid error of a concurrent guess: got %v", err)
+		}
+	}
+	if invalid != MaxFailedLogins {
+		t.Fatalf("invalid number of counted guesses: got %d, want %d", invalid, MaxFailedLogins)
+	}
+
+	if _, err := usecase.Authenticate(ctx, "budi@example.com", "rahasia!"); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("invalid error of the right password after the guesses: got %v, want %v", err, ErrAccountLocked)
+	}
+}
+
+func TestUsecase_ResetPassword(t *testing.T) {
+	ctx := context.Background()
+	usecase, mailer := newMemoryUsecase(t)
+
+	if _, err := usecase.RegisterUser(ctx, &RegisterUserRequest{Email: "budi@example.com", Password: "rahasia!"}); err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+
+	if err := usecase.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "siti@example.com"}); err != nil || mailer.token != "" {
+		t.Fatalf("invalid forgot password of an unknown email: got %v, token %q", err, mailer.token)
+	}
+	if err := usecase.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "budi@example.com"}); err != nil || mailer.token == "" {
+		t.Fatalf("failed to forgot password: %v", err)
+	}
+
+	reset := &ResetPasswordRequest{Token: mailer.token, Password: "rahasia-baru"}
+	if err := usecase.ResetPassword(ctx, reset); err != nil {
+		t.Fatalf("failed to reset password: %v", err)
+	}
+	if err := usecase.ResetPassword(ctx, reset); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("invalid error of a used token: got %v, want %v", err, ErrInvalidResetToken)
+	}
+
+	if _, err := usecase.Authenticate(ctx, "budi@example.com", "rahasia!"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Fatalf("invalid error of the old password: got %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	if _, err := usecase.Authenticate(ctx, "budi@example.com", "rahasia-baru"); err != nil {
+		t.Fatalf("failed to authenticate with the new password: %v", err)
+	}
+
+	// expire a new token
+	if err := usecase.ForgotPassword(ctx, &ForgotPasswordRequest{Email: "budi@example.com"}); err != nil {
+		t.Fatalf("failed to forgot password: %v", err)
+	}
+	err := store.WithTx(ctx, usecase.Tx, nil, func(tx store.Tx) error {
+		expired, err := usecase.Repo.GetPasswordReset(ctx, tx, hashToken(mailer.token))
+		if err != nil {
+			return err
+		}
+		expired.Expires_At = time.Now().Add(-time.Second)
+		return memory.Of(tx).Put(MemoryPasswordResetTable, uint64(expired.ID), *expired)
+	})
+	if err != nil {
+		t.Fatalf("failed to expire the token: %v", err)
+	}
+
+	err = usecase.ResetPassword(ctx, &ResetPasswordRequest{Token: mailer.token, Password: "rahasia-lagi"})
+	if !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("invalid error of an expired token: got %v, want %v", err, ErrInvalidResetToken)
+	}
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"toko-buku-api/pkg/errs"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned for an unknown username or a wrong
+// password, without telling which one.
+var ErrInvalidCredentials = errs.Unauthorized("invalid username or password")
+
+// Credentials checks the password of a user, returning the claims to sign
+// for them.
+type Credentials interface {
+	Authenticate(ctx context.Context, username, password string) (Claims, error)
+}
+
+// KeyVerifier checks an API key, returning the claims of the machine client
+// holding it.
+type KeyVerifier interface {
+	VerifyKey(ctx context.Context, key string) (Claims, error)
+}
+
+// Chain tries the credentials in turn, moving on while they answer
+// ErrInvalidCredentials.
+type Chain []Credentials
+
+func (c Chain) Authenticate(ctx context.Context, username, password string) (Claims, error) {
+	for _, credentials := range c {
+		claims, err := credentials.Authenticate(ctx, username, password)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return claims, err
+		}
+	}
+
+	return Claims{}, ErrInvalidCredentials
+}
+
+// TokenRequest represents the body of a login, with the username of a
+// configured user or the email of a registered one.
+type TokenRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// TokenResponse represents an access token issued by a login.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// StaticUser represents a user configured by hand, with the bcrypt hash of
+// its password.
+type StaticUser struct {
+	Username     string `mapstructure:"username"`
+	PasswordHash string `mapstructure:"passwordHash"`
+	Role         string `mapstructure:"role"`
+}
+
+// StaticUsers checks credentials against a fixed list of users.
+type StaticUsers []StaticUser
+
+// dummyHash is compared for unknown usernames, so they take as long as
+// wrong passwords.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+func (users StaticUsers) Authenticate(ctx context.Context, username, password string) (Claims, error) {
+	for _, user := range users {
+		if user.Username != username {
+			continue
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+			return Claims{}, ErrInvalidCredentials
+		}
+		return Claims{Subject: user.Username, Role: user.Role}, nil
+	}
+
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+	return Claims{}, ErrInvalidCredentials
+}
@@ -0,0 +1,201 @@
+// Package auth signs and verifies the JWT access tokens of the api, with the
+// standard library only.
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"toko-buku-api/pkg/errs"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// A set of errors returned when verifying a token.
+var (
+	ErrInvalidToken = errs.Unauthorized("invalid token")
+	ErrExpiredToken = errs.Unauthorized("token expired")
+)
+
+// Claims represents the claims of an access token, the principal of an
+// authenticated request.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+// JWT signs and verifies tokens with a single algorithm and key.
+type JWT struct {
+	algorithm  string
+	secret     []byte
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+	issuer     string
+	ttl        time.Duration
+
+	// now returns the current time, replaced by tests
+	now func() time.Time
+}
+
+// NewHS256 constructs a JWT signing with HMAC SHA-256 and the secret.
+func NewHS256(secret []byte, issuer string, ttl time.Duration) (*JWT, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("auth: HS256 secret must be at least 32 bytes")
+	}
+
+	return &JWT{algorithm: HS256, secret: secret, issuer: issuer, ttl: ttl, now: time.Now}, nil
+}
+
+// NewRS256 constructs a JWT signing with RSA SHA-256. A nil privateKey only
+// verifies tokens signed elsewhere.
+func NewRS256(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, issuer string, ttl time.Duration) (*JWT, error) {
+	if publicKey == nil && privateKey != nil {
+		publicKey = &privateKey.PublicKey
+	}
+	if publicKey == nil {
+		return nil, errors.New("auth: RS256 needs a public key")
+	}
+
+	return &JWT{algorithm: RS256, privateKey: privateKey, publicKey: publicKey, issuer: issuer, ttl: ttl, now: time.Now}, nil
+}
+
+// TTL returns how long the tokens signed are valid.
+func (j *JWT) TTL() time.Duration {
+	return j.ttl
+}
+
+// Sign returns a token for the claims, filling the issuer and the issue
+// and expiry times.
+func (j *JWT) Sign(claims Claims) (string, error) {
+	now := j.now()
+	claims.Issuer = j.issuer
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(j.ttl).Unix()
+
+	headerJSON, err := json.Marshal(header{Algorithm: j.algorithm, Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encode(headerJSON) + "." + encode(claimsJSON)
+	signature, err := j.sign([]byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + encode(signature), nil
+}
+
+// Verify returns the claims of a token signed with the algorithm and key of
+// j, issued by its issuer and valid now.
+func (j *JWT) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil || h.Algorithm != j.algorithm {
+		return Claims{}, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if err := j.verify([]byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if claims.Subject == "" || (j.issuer != "" && claims.Issuer != j.issuer) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	now := j.now().Unix()
+	if claims.NotBefore > now {
+		return Claims{}, ErrInvalidToken
+	}
+	if claims.ExpiresAt <= now {
+		return Claims{}, ErrExpiredToken
+	}
+
+	return claims, nil
+}
+
+func (j *JWT) sign(input []byte) ([]byte, error) {
+	switch j.algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, j.secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		if j.privateKey == nil {
+			return nil, errors.New("auth: RS256 signing needs a private key")
+		}
+		digest := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, j.privateKey, crypto.SHA256, digest[:])
+	}
+
+	return nil, fmt.Errorf("auth: unsupported algorithm %q", j.algorithm)
+}
+
+func (j *JWT) verify(input, signature []byte) error {
+	switch j.algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, j.secret)
+		mac.Write(input)
+		if subtle.ConstantTimeCompare(mac.Sum(nil), signature) != 1 {
+			return ErrInvalidToken
+		}
+		return nil
+	case RS256:
+		digest := sha256.Sum256(input)
+		return rsa.VerifyPKCS1v15(j.publicKey, crypto.SHA256, digest[:], signature)
+	}
+
+	return fmt.Errorf("auth: unsupported algorithm %q", j.algorithm)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(part string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
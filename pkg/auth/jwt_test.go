@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWT(t *testing.T) {
+	hs256, err := NewHS256([]byte("toko-buku-api-test-secret-0123456789"), "toko-buku-api", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to build HS256: %v", err)
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rs256, err := NewRS256(privateKey, nil, "toko-buku-api", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to build RS256: %v", err)
+	}
+	rs256Verifier, err := NewRS256(nil, &privateKey.PublicKey, "toko-buku-api", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to build RS256 verifier: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		signer   *JWT
+		verifier *JWT
+		tamper   func(token string) string
+		wantErr  error
+	}{
+		{name: "HS256", signer: hs256, verifier: hs256},
+		{name: "RS256", signer: rs256, verifier: rs256Verifier},
+		{name: "other algorithm", signer: hs256, verifier: rs256, wantErr: ErrInvalidToken},
+		{
+			name: "tampered claims", signer: hs256, verifier: hs256, wantErr: ErrInvalidToken,
+			tamper: func(token string) string {
+				parts := strings.Split(token, ".")
+				parts[1] = encode([]byte(`{"sub":"admin","role":"admin","exp":9999999999}`))
+				return strings.Join(parts, ".")
+			},
+		},
+		{
+			name: "none algorithm", signer: hs256, verifier: hs256, wantErr: ErrInvalidToken,
+			tamper: func(token string) string {
+				parts := strings.Split(token, ".")
+				return encode([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + "."
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := tc.signer.Sign(Claims{Subject: "staff", Role: "staff"})
+			if err != nil {
+				t.Fatalf("failed to sign: %v", err)
+			}
+			if tc.tamper != nil {
+				token = tc.tamper(token)
+			}
+
+			claims, err := tc.verifier.Verify(token)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("invalid error: got %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to verify: %v", err)
+			}
+			if claims.Subject != "staff" || claims.Role != "staff" || claims.Issuer != "toko-buku-api" {
+				t.Fatalf("invalid claims: got %+v", claims)
+			}
+		})
+	}
+}
+
+func TestJWT_expired(t *testing.T) {
+	jwt, err := NewHS256([]byte("toko-buku-api-test-secret-0123456789"), "toko-buku-api", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to build HS256: %v", err)
+	}
+
+	token, err := jwt.Sign(Claims{Subject: "staff"})
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	jwt.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := jwt.Verify(token); !errors.Is(err, ErrExpiredToken) {
+		t.Fatalf("invalid error: got %v, want %v", err, ErrExpiredToken)
+	}
+}
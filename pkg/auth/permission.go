@@ -0,0 +1,62 @@
+package auth
+
+import "slices"
+
+// Roles of the users, the names of the rows of the role table.
+const (
+	RoleAdmin    = "admin"
+	RoleStaff    = "staff"
+	RoleCustomer = "customer"
+)
+
+// Permission names an action on a resource, as <resource>:<action>.
+type Permission string
+
+// Permissions the routes require, the names of the rows of the permission
+// table.
+const (
+	APIKeysRead      Permission = "apikeys:read"
+	APIKeysWrite     Permission = "apikeys:write"
+	AuthorsWrite     Permission = "authors:write"
+	AuthorsDelete    Permission = "authors:delete"
+	BooksWrite       Permission = "books:write"
+	BooksDelete      Permission = "books:delete"
+	CountriesWrite   Permission = "countries:write"
+	CountriesDelete  Permission = "countries:delete"
+	OrdersRead       Permission = "orders:read"
+	OrdersReadOwn    Permission = "orders:read-own"
+	OrdersWrite      Permission = "orders:write"
+	OrdersTransition Permission = "orders:transition"
+	TypesWrite       Permission = "types:write"
+	TypesDelete      Permission = "types:delete"
+)
+
+// Permissions lists every permission, the scopes an API key may hold.
+var Permissions = []Permission{
+	APIKeysRead, APIKeysWrite,
+	AuthorsWrite, AuthorsDelete,
+	BooksWrite, BooksDelete,
+	CountriesWrite, CountriesDelete,
+	OrdersRead, OrdersReadOwn, OrdersWrite, OrdersTransition,
+	TypesWrite, TypesDelete,
+}
+
+// Policy maps the roles to the permissions they grant.
+type Policy map[string][]Permission
+
+// Allows reports whether the principal of claims holds the permission. The
+// role grants permissions and the scopes, when present, narrow them; a
+// principal without a role holds its scopes only.
+func (p Policy) Allows(claims Claims, permission Permission) bool {
+	if claims.Role == "" && len(claims.Scopes) == 0 {
+		return false
+	}
+	if claims.Role != "" && !slices.Contains(p[claims.Role], permission) {
+		return false
+	}
+	if len(claims.Scopes) > 0 && !slices.Contains(claims.Scopes, string(permission)) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,35 @@
+package auth
+
+import "testing"
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := Policy{
+		RoleAdmin: {AuthorsWrite, CountriesWrite},
+		RoleStaff: {AuthorsWrite},
+	}
+
+	testCases := []struct {
+		name       string
+		claims     Claims
+		permission Permission
+		want       bool
+	}{
+		{"admin", Claims{Subject: "1", Role: RoleAdmin}, CountriesWrite, true},
+		{"staff", Claims{Subject: "2", Role: RoleStaff}, AuthorsWrite, true},
+		{"staff without permission", Claims{Subject: "2", Role: RoleStaff}, CountriesWrite, false},
+		{"customer", Claims{Subject: "3", Role: RoleCustomer}, AuthorsWrite, false},
+		{"unknown role", Claims{Subject: "4", Role: "root"}, AuthorsWrite, false},
+		{"scoped role", Claims{Subject: "1", Role: RoleAdmin, Scopes: []string{"authors:write"}}, CountriesWrite, false},
+		{"scopes only", Claims{Subject: "key", Scopes: []string{"countries:write"}}, CountriesWrite, true},
+		{"scopes only without permission", Claims{Subject: "key", Scopes: []string{"authors:write"}}, CountriesWrite, false},
+		{"neither", Claims{Subject: "5"}, AuthorsWrite, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.Allows(tc.claims, tc.permission); got != tc.want {
+				t.Fatalf("invalid allows: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
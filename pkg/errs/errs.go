@@ -0,0 +1,170 @@
+// Package errs defines the error taxonomy shared by the repositories and
+// usecases, which the api layer maps to http statuses in one place.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error.
+type Kind int
+
+// Set of error kinds. The zero Kind is Internal, so unclassified errors never
+// leak details to clients.
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindFKViolation
+	KindUnauthorized
+	KindBadRequest
+	KindForbidden
+	KindTooLarge
+	KindUnsupportedMediaType
+	KindPreconditionFailed
+	KindPreconditionRequired
+)
+
+var kindNames = map[Kind]string{
+	KindInternal:             "internal",
+	KindNotFound:             "not found",
+	KindConflict:             "conflict",
+	KindValidation:           "validation",
+	KindFKViolation:          "foreign key violation",
+	KindUnauthorized:         "unauthorized",
+	KindBadRequest:           "bad request",
+	KindForbidden:            "forbidden",
+	KindTooLarge:             "too large",
+	KindUnsupportedMediaType: "unsupported media type",
+	KindPreconditionFailed:   "precondition failed",
+	KindPreconditionRequired: "precondition required",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Error represents an error of a Kind, with a message safe to return to
+// clients and the optional underlying error.
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s: %v", e.Message, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound constructs an error for a missing resource.
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+// Conflict constructs an error for a request conflicting with the current
+// state of a resource.
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+// Validation constructs an error for a request failing validation.
+func Validation(message string) *Error {
+	return &Error{Kind: KindValidation, Message: message}
+}
+
+// FKViolation constructs an error for a request referencing a missing row.
+func FKViolation(message string) *Error {
+	return &Error{Kind: KindFKViolation, Message: message}
+}
+
+// Unauthorized constructs an error for a request without valid credentials.
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+// Forbidden constructs an error for a principal lacking a permission.
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+// BadRequest constructs an error for a malformed request.
+func BadRequest(message string) *Error {
+	return &Error{Kind: KindBadRequest, Message: message}
+}
+
+// TooLarge constructs an error for a request body over the size limit.
+func TooLarge(message string) *Error {
+	return &Error{Kind: KindTooLarge, Message: message}
+}
+
+// UnsupportedMediaType constructs an error for a request body of a content
+// type the api does not read.
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: KindUnsupportedMediaType, Message: message}
+}
+
+// PreconditionFailed constructs an error for a conditional request whose
+// resource changed since the client read it.
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: message}
+}
+
+// PreconditionRequired constructs an error for a write missing the
+// condition it must be made on.
+func PreconditionRequired(message string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Message: message}
+}
+
+// Internal constructs an error for a failure the client cannot fix.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Message: "internal error", Err: err}
+}
+
+// Invalid classifies err, the failed validation of a request body, as a
+// validation error.
+func Invalid(err error) *Error {
+	return Wrap(KindValidation, "invalid request body", err)
+}
+
+// Wrap classifies err as kind with the message, keeping err unwrappable.
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// KindOf returns the Kind of the first Error in the chain of err, or
+// KindInternal when there is none.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return KindInternal
+}
+
+// MessageOf returns the client message of the first Error in the chain of
+// err, or the message of an internal error. The context wrapped around an
+// Error without an underlying error, like the id of a missing row, is part
+// of the message.
+func MessageOf(err error) string {
+	var e *Error
+	if !errors.As(err, &e) {
+		return Internal(err).Message
+	}
+	if e.Err != nil {
+		return e.Message
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,40 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	errBookNotFound := NotFound("book not found")
+	cause := errors.New("duplicate entry")
+
+	testCases := []struct {
+		name        string
+		err         error
+		wantKind    Kind
+		wantMessage string
+	}{
+		{"sentinel", errBookNotFound, KindNotFound, "book not found"},
+		{"wrapped sentinel", fmt.Errorf("book %d: %w", 1, errBookNotFound), KindNotFound, "book 1: book not found"},
+		{"wrapped wrap", fmt.Errorf("book %d: %w", 1, Wrap(KindConflict, "sku already exists", cause)), KindConflict, "sku already exists"},
+		{"wrap", Wrap(KindConflict, "sku already exists", cause), KindConflict, "sku already exists"},
+		{"plain error", cause, KindInternal, "internal error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if kind := KindOf(tc.err); kind != tc.wantKind {
+				t.Fatalf("invalid kind: got %s, want %s", kind, tc.wantKind)
+			}
+			if message := MessageOf(tc.err); message != tc.wantMessage {
+				t.Fatalf("invalid message: got %q, want %q", message, tc.wantMessage)
+			}
+		})
+	}
+
+	if !errors.Is(Wrap(KindConflict, "sku already exists", cause), cause) {
+		t.Fatalf("invalid unwrap: cause not found")
+	}
+}
@@ -0,0 +1,90 @@
+// Package etag computes the strong entity tags of resources and evaluates
+// the If-Match and If-None-Match preconditions against them, RFC 9110.
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// encodings are the content codings of the representations tagged by
+// Encoded, the encodings web.Compress negotiates.
+var encodings = []string{"gzip", "deflate"}
+
+// Tagger is implemented by the resources whose entity tag covers part of
+// their representation, like a row without the rows joined to it, which
+// change on their own.
+type Tagger interface {
+	ETag() (string, error)
+}
+
+// Of returns the strong entity tag of v, the quoted sha256 of its json
+// encoding, so it changes with any field of the representation, updated_at
+// included. The Taggers return their own.
+func Of(v any) (string, error) {
+	if tagger, ok := v.(Tagger); ok {
+		return tagger.ETag()
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// Encoded returns the strong entity tag of the representation tagged tag
+// once encoded with the content coding, distinct from the unencoded one:
+// "<hash>-gzip" for "<hash>". Weak tags are left as they are.
+func Encoded(tag, encoding string) string {
+	if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+		return tag
+	}
+
+	return strings.TrimSuffix(tag, `"`) + "-" + encoding + `"`
+}
+
+// decoded returns the entity tag of the unencoded representation of a tag
+// returned by Encoded, and other tags as they are.
+func decoded(tag string) string {
+	for _, encoding := range encodings {
+		if unencoded, ok := strings.CutSuffix(tag, "-"+encoding+`"`); ok {
+			return unencoded + `"`
+		}
+	}
+
+	return tag
+}
+
+// Match reports whether header, the value of an If-Match or If-None-Match
+// header, lists tag or is "*". Weak tags never match with the strong
+// comparison of If-Match, and match their strong counterpart with the weak
+// comparison of If-None-Match. The tags of the encoded representations
+// match the tag of the unencoded one, the state of the resource being the
+// same.
+func Match(header, tag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	tag = decoded(strings.TrimPrefix(tag, "W/"))
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+
+		if decoded(candidate) == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package etag
+
+import "testing"
+
+func TestOf(t *testing.T) {
+	type country struct {
+		ID      uint8
+		Country string
+	}
+
+	tag, err := Of(country{ID: 100, Country: "INDONESIA"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tag) != 34 || tag[0] != '"' || tag[33] != '"' {
+		t.Fatalf("invalid tag: got %s", tag)
+	}
+
+	same, _ := Of(country{ID: 100, Country: "INDONESIA"})
+	changed, _ := Of(country{ID: 100, Country: "Indonesia"})
+	if same != tag || changed == tag {
+		t.Fatalf("invalid tags: got %s and %s for %s", same, changed, tag)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tag := `"0123456789abcdef"`
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{"same", `"0123456789abcdef"`, false, true},
+		{"any", `*`, false, true},
+		{"listed", `"fedcba9876543210", "0123456789abcdef"`, false, true},
+		{"other", `"fedcba9876543210"`, false, false},
+		{"empty", ``, false, false},
+		{"unquoted", `0123456789abcdef`, false, false},
+		{"weak strong comparison", `W/"0123456789abcdef"`, false, false},
+		{"weak weak comparison", `W/"0123456789abcdef"`, true, true},
+		{"gzip", `"0123456789abcdef-gzip"`, false, true},
+		{"deflate weak comparison", `W/"0123456789abcdef-deflate"`, true, true},
+		{"other encoding", `"0123456789abcdef-br"`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.header, tag, tt.weak); got != tt.want {
+				t.Fatalf("Match(%q): got %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoded(t *testing.T) {
+	tests := map[string]string{
+		`"0123456789abcdef"`:   `"0123456789abcdef-gzip"`,
+		`W/"0123456789abcdef"`: `W/"0123456789abcdef"`,
+		``:                     ``,
+	}
+
+	for tag, want := range tests {
+		if got := Encoded(tag, "gzip"); got != want {
+			t.Errorf("Encoded(%q): got %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestOf_tagger(t *testing.T) {
+	tag, err := Of(tagger(`"0123456789abcdef"`))
+	if err != nil || tag != `"0123456789abcdef"` {
+		t.Fatalf("invalid tag of a Tagger: got %s %v", tag, err)
+	}
+}
+
+type tagger string
+
+func (t tagger) ETag() (string, error) {
+	return string(t), nil
+}
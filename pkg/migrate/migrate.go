@@ -0,0 +1,374 @@
+// Package migrate applies the embedded SQL migrations to MySQL, tracking the
+// applied versions with their checksum in a schema table and holding a named
+// lock so that concurrent deploys do not migrate twice.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"toko-buku-api/pkg/logger"
+)
+
+// A set of errors returned by the migrator.
+var (
+	ErrDirty       = errors.New("migrate: database is dirty, fix the failed version and force it")
+	ErrChecksum    = errors.New("migrate: applied migration was modified")
+	ErrLocked      = errors.New("migrate: lock is held by another migration")
+	ErrNoMigration = errors.New("migrate: no such migration")
+	ErrNoDown      = errors.New("migrate: migration has no down file")
+)
+
+// SchemaTable is the table tracking the applied versions.
+const SchemaTable = "schema_migration"
+
+// lockName is the MySQL named lock held during a migration.
+const lockName = "toko-buku-api.migrate"
+
+// lockTimeout is the number of seconds to wait for the lock.
+const lockTimeout = 10
+
+const createSchemaTable = "CREATE TABLE IF NOT EXISTS " + SchemaTable + ` (
+    version BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    dirty TINYINT(1) NOT NULL DEFAULT 0,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version))
+ENGINE = InnoDB`
+
+// Status represents the state of one migration.
+type Status struct {
+	Migration
+	Applied   bool
+	Dirty     bool
+	Modified  bool
+	AppliedAt *time.Time
+}
+
+// applied is a row of the schema table.
+type applied struct {
+	version   uint64
+	name      string
+	checksum  string
+	dirty     bool
+	appliedAt time.Time
+}
+
+// Migrator runs migrations against a database.
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+	Log        *logger.Logger
+}
+
+// New constructs a migrator for the migrations ordered by version.
+func New(db *sql.DB, migrations []Migration, log *logger.Logger) *Migrator {
+	return &Migrator{
+		DB:         db,
+		Migrations: migrations,
+		Log:        log,
+	}
+}
+
+// Up applies the next n pending migrations, or all of them when n is 0.
+func (m *Migrator) Up(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, migration := range m.Migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if n > 0 && count == n {
+				break
+			}
+
+			if err := m.up(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+
+		if count == 0 {
+			m.Log.Info(ctx, "migrate", "status", "no change")
+		}
+		return nil
+	})
+}
+
+// Down rolls the last n applied migrations back, newest first. Versions are
+// ordered by foreign key dependency, so a table is dropped before the tables
+// it references.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for i := len(m.Migrations) - 1; i >= 0 && count < n; i-- {
+			if _, ok := versions[m.Migrations[i].Version]; !ok {
+				continue
+			}
+
+			if err := m.down(ctx, conn, m.Migrations[i]); err != nil {
+				return err
+			}
+			count++
+		}
+
+		if count == 0 {
+			m.Log.Info(ctx, "migrate", "status", "no change")
+		}
+		return nil
+	})
+}
+
+// To migrates up or down until version is the last applied migration, a zero
+// version rolls every migration back.
+func (m *Migrator) To(ctx context.Context, version uint64) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrNoMigration, version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := m.checkApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0; i-- {
+			migration := m.Migrations[i]
+			if _, ok := versions[migration.Version]; ok && migration.Version > version {
+				if err := m.down(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range m.Migrations {
+			if _, ok := versions[migration.Version]; !ok && migration.Version <= version {
+				if err := m.up(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Force marks every migration up to version as applied and clean without
+// running them, and every later one as pending. It recovers a dirty database
+// once the failed migration was fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrNoMigration, version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM "+SchemaTable); err != nil {
+			return fmt.Errorf("migrate: force: %w", err)
+		}
+
+		for _, migration := range m.Migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := m.record(ctx, conn, migration, false); err != nil {
+				return err
+			}
+		}
+
+		m.Log.Info(ctx, "migrate", "status", "forced", "version", version)
+		return nil
+	})
+}
+
+// Status reports every known migration along with applied versions whose
+// file no longer exists.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: connect: %w", err)
+	}
+	defer conn.Close()
+
+	versions, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		status := Status{Migration: migration}
+		if row, ok := versions[migration.Version]; ok {
+			status.Applied = true
+			status.Dirty = row.dirty
+			status.Modified = row.checksum != migration.Checksum
+			status.AppliedAt = &row.appliedAt
+			delete(versions, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range versions {
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.version, Name: row.name, Checksum: row.checksum},
+			Applied:   true,
+			Dirty:     row.dirty,
+			AppliedAt: &row.appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) up(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if err := m.record(ctx, conn, migration, true); err != nil {
+		return err
+	}
+
+	if err := exec(ctx, conn, migration.Up); err != nil {
+		return fmt.Errorf("migrate: up %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	query := "UPDATE " + SchemaTable + " SET dirty = 0 WHERE version = ?"
+	if _, err := conn.ExecContext(ctx, query, migration.Version); err != nil {
+		return fmt.Errorf("migrate: up %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.Log.Info(ctx, "migrate", "status", "applied", "version", migration.Version, "name", migration.Name)
+	return nil
+}
+
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrNoDown, migration.Version, migration.Name)
+	}
+
+	query := "UPDATE " + SchemaTable + " SET dirty = 1 WHERE version = ?"
+	if _, err := conn.ExecContext(ctx, query, migration.Version); err != nil {
+		return fmt.Errorf("migrate: down %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := exec(ctx, conn, migration.Down); err != nil {
+		return fmt.Errorf("migrate: down %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	query = "DELETE FROM " + SchemaTable + " WHERE version = ?"
+	if _, err := conn.ExecContext(ctx, query, migration.Version); err != nil {
+		return fmt.Errorf("migrate: down %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	m.Log.Info(ctx, "migrate", "status", "rolled back", "version", migration.Version, "name", migration.Name)
+	return nil
+}
+
+// record inserts the migration into the schema table, a dirty row stays
+// behind when the migration fails halfway.
+func (m *Migrator) record(ctx context.Context, conn *sql.Conn, migration Migration, dirty bool) error {
+	query := "INSERT INTO " + SchemaTable + "(version, name, checksum, dirty) VALUES (?, ?, ?, ?)"
+	if _, err := conn.ExecContext(ctx, query, migration.Version, migration.Name, migration.Checksum, dirty); err != nil {
+		return fmt.Errorf("migrate: record %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+// checkApplied returns the applied versions, failing on a dirty database or a
+// migration modified since it was applied.
+func (m *Migrator) checkApplied(ctx context.Context, conn *sql.Conn) (map[uint64]applied, error) {
+	versions, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range versions {
+		if row.dirty {
+			return nil, fmt.Errorf("%w: version %d", ErrDirty, row.version)
+		}
+
+		if i := m.find(row.version); i >= 0 && m.Migrations[i].Checksum != row.checksum {
+			return nil, fmt.Errorf("%w: version %d", ErrChecksum, row.version)
+		}
+	}
+
+	return versions, nil
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[uint64]applied, error) {
+	if _, err := conn.ExecContext(ctx, createSchemaTable); err != nil {
+		return nil, fmt.Errorf("migrate: create schema table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, dirty, applied_at FROM "+SchemaTable)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read schema table: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[uint64]applied)
+	for rows.Next() {
+		var row applied
+		if err := rows.Scan(&row.version, &row.name, &row.checksum, &row.dirty, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("migrate: read schema table: %w", err)
+		}
+		versions[row.version] = row
+	}
+
+	return versions, rows.Err()
+}
+
+// withLock runs fn on a single connection holding the migration lock, since
+// MySQL named locks belong to the session.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: connect: %w", err)
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&locked); err != nil {
+		return fmt.Errorf("migrate: lock: %w", err)
+	}
+	if locked.Int64 != 1 {
+		return ErrLocked
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName); err != nil {
+			m.Log.Error(ctx, "migrate", "status", "failed to release lock", "error", err)
+		}
+	}()
+
+	return fn(conn)
+}
+
+func (m *Migrator) find(version uint64) int {
+	for i, migration := range m.Migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func exec(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"toko-buku-api/db/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"2_create_table_author.up.sql":   {Data: []byte("CREATE TABLE author (id INT);")},
+		"2_create_table_author.down.sql": {Data: []byte("DROP TABLE author;")},
+		"1_create_table_country.up.sql":  {Data: []byte("CREATE TABLE country (id INT);")},
+		"README.md":                      {Data: []byte("not a migration")},
+	}
+
+	loaded, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	if len(loaded) != 2 || loaded[0].Version != 1 || loaded[1].Name != "create_table_author" {
+		t.Fatalf("invalid migrations: got %+v", loaded)
+	}
+	if loaded[1].Down != "DROP TABLE author;" || len(loaded[0].Checksum) != 64 {
+		t.Fatalf("invalid migration content: got %+v", loaded[1])
+	}
+
+	delete(fsys, "1_create_table_country.up.sql")
+	fsys["1_create_table_country.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE country;")}
+	if _, err := Load(fsys); err == nil {
+		t.Fatalf("invalid error for a version without up file: got nil")
+	}
+}
+
+func TestLoad_embedded(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("failed to load embedded migrations: %v", err)
+	}
+
+	for _, migration := range loaded {
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- country table
+CREATE TABLE country (name VARCHAR(50) COMMENT 'a; b');
+/* seed */ INSERT INTO country (name) VALUES ('Indonesia'), ("it\'s; fine");
+
+# done
+COMMIT;`
+
+	expected := []string{
+		"CREATE TABLE country (name VARCHAR(50) COMMENT 'a; b')",
+		`INSERT INTO country (name) VALUES ('Indonesia'), ("it\'s; fine")`,
+		"COMMIT",
+	}
+
+	if statements := splitStatements(script); !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("invalid statements: got %q", statements)
+	}
+}
+
+func TestMigrator_unknownVersion(t *testing.T) {
+	m := New(nil, []Migration{{Version: 1, Name: "create_table_country"}}, nil)
+
+	if err := m.To(context.Background(), 2); !errors.Is(err, ErrNoMigration) {
+		t.Fatalf("invalid error: got %v, want ErrNoMigration", err)
+	}
+	if err := m.Force(context.Background(), 2); !errors.Is(err, ErrNoMigration) {
+		t.Fatalf("invalid error: got %v, want ErrNoMigration", err)
+	}
+}
+
+var (
+	createsTable = regexp.MustCompile("(?i)CREATE TABLE IF NOT EXISTS `?(\\w+)`?")
+	references   = regexp.MustCompile("(?i)REFERENCES `?(\\w+)`?")
+	dropsTable   = regexp.MustCompile("(?i)DROP TABLE")
+)
+
+// TestLoad_embeddedOrder checks that every table is created after the tables
+// it references, so rolling versions back newest first respects foreign keys,
+// and that only down files drop tables.
+func TestLoad_embeddedOrder(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("failed to load embedded migrations: %v", err)
+	}
+
+	created := make(map[string]bool)
+	for _, migration := range loaded {
+		if dropsTable.MatchString(migration.Up) {
+			t.Errorf("migration %d_%s drops a table on up", migration.Version, migration.Name)
+		}
+
+		for _, match := range references.FindAllStringSubmatch(migration.Up, -1) {
+			if !created[match[1]] {
+				t.Errorf("migration %d_%s references %s before it is created", migration.Version, migration.Name, match[1])
+			}
+		}
+
+		for _, match := range createsTable.FindAllStringSubmatch(migration.Up, -1) {
+			created[match[1]] = true
+		}
+	}
+}
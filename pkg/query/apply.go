@@ -0,0 +1,205 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the accepted filter formats for time fields.
+var timeLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// Apply evaluates the options on in-memory items the way Select and Count do
+// in SQL, for repositories without a database. valueFn must return the value
+// of every field of the spec.
+func Apply[T any](o *Options, items []T, valueFn ValuesFn[T]) ([]T, *Page, error) {
+	var filtered []T
+	for _, item := range items {
+		ok, err := match(o, item, valueFn)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			filtered = append(filtered, item)
+		}
+	}
+	total := len(filtered)
+
+	slices.SortStableFunc(filtered, func(a, b T) int {
+		for _, sort := range o.Sorts {
+			c := compare(valueFn(a, sort.Field), valueFn(b, sort.Field))
+			if sort.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	start := 0
+	switch {
+	case o.Cursor != nil:
+		start = len(filtered)
+		for i, item := range filtered {
+			if afterCursor(o, item, valueFn) {
+				start = i
+				break
+			}
+		}
+	case o.Offset > 0:
+		start = min(o.Offset, len(filtered))
+	}
+
+	end := min(start+o.Limit+1, len(filtered))
+	return Paginate(o, filtered[start:end], total, valueFn)
+}
+
+// match reports whether the item passes every filter, a NULL value never does.
+func match[T any](o *Options, item T, valueFn ValuesFn[T]) (bool, error) {
+	for _, filter := range o.Filters {
+		value := deref(valueFn(item, filter.Field))
+		if value == nil {
+			return false, nil
+		}
+
+		if filter.Operator == OpContains {
+			contains := strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(filter.Value))
+			if !contains {
+				return false, nil
+			}
+			continue
+		}
+
+		want, err := parseAs(filter.Value, value)
+		if err != nil {
+			return false, err
+		}
+
+		c := compare(value, want)
+		var ok bool
+		switch filter.Operator {
+		case OpEqual:
+			ok = c == 0
+		case OpNotEqual:
+			ok = c != 0
+		case OpGreaterEqual:
+			ok = c >= 0
+		case OpLessEqual:
+			ok = c <= 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// afterCursor reports whether the item sorts after the cursor position.
+func afterCursor[T any](o *Options, item T, valueFn ValuesFn[T]) bool {
+	for i, sort := range o.Sorts {
+		c := compare(valueFn(item, sort.Field), o.Cursor.Values[i])
+		if sort.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c > 0
+		}
+	}
+
+	return false
+}
+
+// compare orders two field values, NULL first and strings case-insensitively
+// like the default MySQL collation.
+func compare(a, b any) int {
+	a, b = deref(a), deref(b)
+
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(strings.ToLower(a), strings.ToLower(fmt.Sprint(b)))
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// parseAs converts a filter value to the type of the field value.
+func parseAs(value string, like any) (any, error) {
+	switch like.(type) {
+	case string:
+		return value, nil
+	case time.Time:
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q is not a time", ErrInvalidQuery, value)
+	}
+
+	if _, ok := toFloat(like); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a number", ErrInvalidQuery, value)
+		}
+		return f, nil
+	}
+
+	return value, nil
+}
+
+func deref(value any) any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer {
+		return value
+	}
+	if v.IsNil() {
+		return nil
+	}
+
+	return v.Elem().Interface()
+}
+
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,193 @@
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cursorKey signs the cursors, so the clients cannot forge the values put in
+// the WHERE clauses. It is random until SetCursorKey is called, which makes
+// the cursors of an instance invalid on the others and after a restart.
+var cursorKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("query: failed to generate the cursor key: %v", err))
+	}
+	return key
+}()
+
+// SetCursorKey sets the key signing the cursors, shared by the instances
+// serving the same clients. It must be called before serving requests.
+func SetCursorKey(key []byte) {
+	cursorKey = key
+}
+
+// Cursor represents the position after the last row of a page.
+type Cursor struct {
+	// Sort is the sort order the cursor was created for.
+	Sort string
+
+	// Values holds the sort field values of the last row.
+	Values []any
+}
+
+// cursorJSON is the signed form of a Cursor, its values written as json
+// strings, numbers or RFC 3339 times and read back as the type of their field.
+type cursorJSON struct {
+	Sort   string            `json:"sort"`
+	Values []json.RawMessage `json:"values"`
+}
+
+// Page represents the pagination result of a list query.
+type Page struct {
+	Total      int
+	Limit      int
+	Offset     int
+	Cursor     bool
+	HasNext    bool
+	NextCursor string
+}
+
+// ValuesFn returns the value of a sort field for an item.
+type ValuesFn[T any] func(item T, field string) any
+
+// Paginate trims the extra row requested by Select and builds the page,
+// encoding the next cursor from the last item.
+func Paginate[T any](o *Options, items []T, total int, valueFn ValuesFn[T]) ([]T, *Page, error) {
+	page := Page{
+		Total:  total,
+		Limit:  o.Limit,
+		Offset: o.Offset,
+		Cursor: o.Cursor != nil,
+	}
+
+	if len(items) <= o.Limit {
+		return items, &page, nil
+	}
+
+	items = items[:o.Limit]
+	last := items[len(items)-1]
+
+	cursor := Cursor{Sort: o.sortKey()}
+	for _, sort := range o.Sorts {
+		cursor.Values = append(cursor.Values, valueFn(last, sort.Field))
+	}
+
+	next, err := encodeCursor(cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	page.HasNext = true
+	page.NextCursor = next
+
+	return items, &page, nil
+}
+
+// encodeCursor returns the cursor as base64 json followed by its signature,
+// separated by a dot.
+func encodeCursor(cursor Cursor) (string, error) {
+	encoded := cursorJSON{Sort: cursor.Sort}
+	for _, value := range cursor.Values {
+		value = deref(value)
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339Nano)
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("encode cursor: %w", err)
+		}
+		encoded.Values = append(encoded.Values, raw)
+	}
+
+	payload, err := json.Marshal(encoded)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signCursor(payload)), nil
+}
+
+func signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// decodeCursor verifies the signature of the cursor and reads its values as
+// the types of the sort fields of the spec.
+func decodeCursor(value string, sorts []Sort, spec *Spec) (*Cursor, error) {
+	malformed := fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+
+	encodedPayload, encodedSignature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, malformed
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, malformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, signCursor(payload)) {
+		return nil, malformed
+	}
+
+	var encoded cursorJSON
+	if err := json.Unmarshal(payload, &encoded); err != nil {
+		return nil, malformed
+	}
+
+	sortKey := sortKeyOf(sorts)
+	if encoded.Sort != sortKey || len(encoded.Values) != len(sorts) {
+		return nil, fmt.Errorf("%w: cursor does not match sort %q", ErrInvalidQuery, sortKey)
+	}
+
+	cursor := Cursor{Sort: encoded.Sort}
+	for i, sort := range sorts {
+		value, err := decodeValue(encoded.Values[i], spec.Types[sort.Field])
+		if err != nil {
+			return nil, malformed
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	return &cursor, nil
+}
+
+// decodeValue reads a cursor value of the field type: a string, a time, or
+// a number read as uint64, int64 or float64, whichever holds it.
+func decodeValue(raw json.RawMessage, fieldType Type) (any, error) {
+	switch fieldType {
+	case TypeString:
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	case TypeTime:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case TypeNumber:
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, err
+		}
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		if i, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+			return i, nil
+		}
+		return n.Float64()
+	}
+
+	return nil, fmt.Errorf("unknown field type %q", fieldType)
+}
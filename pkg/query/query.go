@@ -0,0 +1,246 @@
+// Package query parses list query strings (pagination, sorting and filters)
+// and translates them into parameterized SQL fragments.
+//
+//	GET /authors?limit=10&sort=author,-updated_at&country_id=100&city~=Jawa
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"toko-buku-api/pkg/errs"
+)
+
+// A set of default pagination values.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Reserved query parameters, every other parameter is a filter.
+const (
+	paramLimit  = "limit"
+	paramOffset = "offset"
+	paramCursor = "cursor"
+	paramSort   = "sort"
+)
+
+// ErrInvalidQuery is returned for query strings that cannot be applied.
+var ErrInvalidQuery = errs.BadRequest("invalid query")
+
+// Operator represents a filter comparison.
+type Operator string
+
+// A set of supported filter operators, as written in the query string.
+const (
+	OpEqual        Operator = "="
+	OpNotEqual     Operator = "!="
+	OpContains     Operator = "~="
+	OpGreaterEqual Operator = ">="
+	OpLessEqual    Operator = "<="
+)
+
+// Type represents the type of the values of a field, which cursors are
+// checked against.
+type Type string
+
+// A set of field types.
+const (
+	TypeNumber Type = "number"
+	TypeString Type = "string"
+	TypeTime   Type = "time"
+)
+
+// Spec describes which fields of a resource may be used in a query.
+type Spec struct {
+	// Columns maps the field names of the query string to SQL columns,
+	// every column is filterable.
+	Columns map[string]string
+
+	// Sortable lists the fields allowed in sort, they must be NOT NULL
+	// columns for cursor pagination to work.
+	Sortable []string
+
+	// Key is the unique field used to break ties in the sort order.
+	Key string
+
+	// Types maps the sortable fields and the key to the type of their
+	// values.
+	Types map[string]Type
+}
+
+// Sort represents one field of the sort order.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Filter represents one field filter.
+type Filter struct {
+	Field    string
+	Operator Operator
+	Value    string
+}
+
+// Options represents the parsed query of a list request.
+type Options struct {
+	Limit   int
+	Offset  int
+	Cursor  *Cursor
+	Sorts   []Sort
+	Filters []Filter
+
+	spec *Spec
+}
+
+// Parse reads the query string values against the resource spec.
+func Parse(values url.Values, spec *Spec) (*Options, error) {
+	options := Options{
+		Limit: DefaultLimit,
+		spec:  spec,
+	}
+
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		value := vals[0]
+
+		switch key {
+		case paramLimit:
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 1 || limit > MaxLimit {
+				return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxLimit)
+			}
+			options.Limit = limit
+
+		case paramOffset:
+			offset, err := strconv.Atoi(value)
+			if err != nil || offset < 0 {
+				return nil, fmt.Errorf("%w: offset must be a positive number", ErrInvalidQuery)
+			}
+			options.Offset = offset
+
+		case paramSort:
+			sorts, err := parseSorts(value, spec)
+			if err != nil {
+				return nil, err
+			}
+			options.Sorts = sorts
+
+		case paramCursor:
+			// decoded once the sort order is known
+
+		default:
+			filter, err := parseFilter(key, value, spec)
+			if err != nil {
+				return nil, err
+			}
+			options.Filters = append(options.Filters, filter)
+		}
+	}
+
+	options.Sorts = withKey(options.Sorts, spec.Key)
+
+	if cursor := values.Get(paramCursor); cursor != "" {
+		if options.Offset > 0 {
+			return nil, fmt.Errorf("%w: cursor and offset cannot be combined", ErrInvalidQuery)
+		}
+
+		decoded, err := decodeCursor(cursor, options.Sorts, spec)
+		if err != nil {
+			return nil, err
+		}
+		options.Cursor = decoded
+	}
+
+	return &options, nil
+}
+
+func parseSorts(value string, spec *Spec) ([]Sort, error) {
+	var sorts []Sort
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		sort := Sort{Field: field}
+		if strings.HasPrefix(field, "-") {
+			sort = Sort{Field: field[1:], Desc: true}
+		}
+
+		if !spec.sortable(sort.Field) {
+			return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, sort.Field)
+		}
+		sorts = append(sorts, sort)
+	}
+
+	return sorts, nil
+}
+
+// parseFilter reads filters like `country_id=100`, which url.Values keeps
+// as the pair "country_id" and "100", or `city~=Jawa` kept as "city~" and "Jawa".
+func parseFilter(key, value string, spec *Spec) (Filter, error) {
+	filter := Filter{Field: key, Operator: OpEqual, Value: value}
+
+	suffixes := map[string]Operator{
+		"!": OpNotEqual,
+		"~": OpContains,
+		">": OpGreaterEqual,
+		"<": OpLessEqual,
+	}
+	for suffix, operator := range suffixes {
+		if strings.HasSuffix(key, suffix) {
+			filter.Field = strings.TrimSuffix(key, suffix)
+			filter.Operator = operator
+			break
+		}
+	}
+
+	if _, ok := spec.Columns[filter.Field]; !ok {
+		return Filter{}, fmt.Errorf("%w: cannot filter by %q", ErrInvalidQuery, filter.Field)
+	}
+
+	return filter, nil
+}
+
+// withKey appends the spec key to the sort order so that it is total.
+func withKey(sorts []Sort, key string) []Sort {
+	for _, sort := range sorts {
+		if sort.Field == key {
+			return sorts
+		}
+	}
+
+	return append(sorts, Sort{Field: key})
+}
+
+func (s *Spec) sortable(field string) bool {
+	for _, sortable := range s.Sortable {
+		if sortable == field {
+			return true
+		}
+	}
+
+	return field == s.Key
+}
+
+// sortKey returns the sort order as written in the query string.
+func (o *Options) sortKey() string {
+	return sortKeyOf(o.Sorts)
+}
+
+func sortKeyOf(sorts []Sort) string {
+	fields := make([]string, len(sorts))
+	for i, sort := range sorts {
+		fields[i] = sort.Field
+		if sort.Desc {
+			fields[i] = "-" + sort.Field
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,228 @@
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSpec = Spec{
+	Columns: map[string]string{
+		"id":         "a.id",
+		"author":     "a.author",
+		"city":       "a.city",
+		"country_id": "a.country_id",
+		"updated_at": "a.updated_at",
+	},
+	Sortable: []string{"author", "updated_at"},
+	Key:      "id",
+	Types: map[string]Type{
+		"id":         TypeNumber,
+		"author":     TypeString,
+		"updated_at": TypeTime,
+	},
+}
+
+func TestParse_select(t *testing.T) {
+	values, _ := url.ParseQuery("limit=10&offset=20&sort=author,-updated_at&country_id=100&city~=Ja_wa")
+
+	options, err := Parse(values, &testSpec)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	query, args := options.Select("SELECT a.id FROM author a", false)
+
+	// filters come from a map, so accept both orders
+	expectedQueries := []string{
+		"SELECT a.id FROM author a WHERE a.country_id = ? AND a.city LIKE ? ORDER BY a.author ASC, a.updated_at DESC, a.id ASC LIMIT ? OFFSET ?",
+		"SELECT a.id FROM author a WHERE a.city LIKE ? AND a.country_id = ? ORDER BY a.author ASC, a.updated_at DESC, a.id ASC LIMIT ? OFFSET ?",
+	}
+	if query != expectedQueries[0] && query != expectedQueries[1] {
+		t.Fatalf("invalid query: got %s", query)
+	}
+
+	if len(args) != 4 || args[2] != 11 || args[3] != 20 {
+		t.Fatalf("invalid args: got %v", args)
+	}
+
+	for _, arg := range args[:2] {
+		if arg != "100" && arg != `%Ja\_wa%` {
+			t.Fatalf("invalid filter arg: got %v", arg)
+		}
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	testCases := []string{
+		"limit=0",
+		"limit=1000",
+		"offset=-1",
+		"sort=password",
+		"password=secret",
+		"cursor=not-a-cursor",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			values, _ := url.ParseQuery(tc)
+			if _, err := Parse(values, &testSpec); !errors.Is(err, ErrInvalidQuery) {
+				t.Fatalf("invalid error: got %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
+
+func TestPaginate_cursor(t *testing.T) {
+	type author struct {
+		ID     uint16
+		Author string
+	}
+	valueFn := func(a author, field string) any {
+		if field == "author" {
+			return a.Author
+		}
+		return a.ID
+	}
+
+	values, _ := url.ParseQuery("limit=2&sort=-author")
+	options, err := Parse(values, &testSpec)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	items := []author{{3, "Pramoedya"}, {1, "Buya Hamka"}, {2, "Amir"}}
+	page, result, err := Paginate(options, items, 3, valueFn)
+	if err != nil {
+		t.Fatalf("failed to paginate: %v", err)
+	}
+
+	if len(page) != 2 || !result.HasNext || result.NextCursor == "" || result.Total != 3 {
+		t.Fatalf("invalid page: got %v %+v", page, result)
+	}
+
+	values.Set("cursor", result.NextCursor)
+	next, err := Parse(values, &testSpec)
+	if err != nil {
+		t.Fatalf("failed to parse next cursor: %v", err)
+	}
+
+	if !reflect.DeepEqual(next.Cursor.Values, []any{"Buya Hamka", uint64(1)}) {
+		t.Fatalf("invalid cursor values: got %v", next.Cursor.Values)
+	}
+
+	query, args := next.Select("SELECT a.id FROM author a", false)
+	expectedQuery := "SELECT a.id FROM author a WHERE ((a.author < ?) OR (a.author = ? AND a.id > ?)) ORDER BY a.author DESC, a.id ASC LIMIT ?"
+	if query != expectedQuery {
+		t.Fatalf("invalid query: got %s, want %s", query, expectedQuery)
+	}
+	if !reflect.DeepEqual(args, []any{"Buya Hamka", "Buya Hamka", uint64(1), 3}) {
+		t.Fatalf("invalid args: got %v", args)
+	}
+
+	values.Set("sort", "author")
+	if _, err := Parse(values, &testSpec); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("invalid error for changed sort: got %v", err)
+	}
+}
+
+func TestParse_cursor(t *testing.T) {
+	updatedAt := time.Date(2026, time.October, 17, 9, 0, 0, 123456000, time.UTC)
+	signed, err := encodeCursor(Cursor{Sort: "-updated_at,id", Values: []any{&updatedAt, uint16(7)}})
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
+	}
+
+	values := url.Values{"sort": {"-updated_at"}, "cursor": {signed}}
+	options, err := Parse(values, &testSpec)
+	if err != nil {
+		t.Fatalf("failed to parse cursor: %v", err)
+	}
+	if !reflect.DeepEqual(options.Cursor.Values, []any{updatedAt, uint64(7)}) {
+		t.Fatalf("invalid cursor values: got %#v", options.Cursor.Values)
+	}
+
+	payload, signature, _ := strings.Cut(signed, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sort":"-updated_at,id","values":["2026-10-17T09:00:00Z",1]}`))
+	mistyped, err := encodeCursor(Cursor{Sort: "-updated_at,id", Values: []any{"yesterday", uint16(7)}})
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
+	}
+
+	testCases := map[string]string{
+		"unsigned":        payload,
+		"forged":          forged + "." + signature,
+		"other signature": payload + "." + base64.RawURLEncoding.EncodeToString([]byte("signature")),
+		"mistyped value":  mistyped,
+	}
+	for name, cursor := range testCases {
+		t.Run(name, func(t *testing.T) {
+			values.Set("cursor", cursor)
+			if _, err := Parse(values, &testSpec); !errors.Is(err, ErrInvalidQuery) {
+				t.Fatalf("invalid error: got %v, want ErrInvalidQuery", err)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	type author struct {
+		ID        uint16
+		CountryID uint8
+		Author    string
+		City      string
+	}
+	valueFn := func(a author, field string) any {
+		switch field {
+		case "country_id":
+			return a.CountryID
+		case "author":
+			return a.Author
+		case "city":
+			return a.City
+		default:
+			return a.ID
+		}
+	}
+
+	items := []author{
+		{1, 100, "Buya Hamka", "Sumatera Barat, Indonesia"},
+		{2, 100, "Pramoedya Ananta Toer", "Jawa Timur, Indonesia"},
+		{3, 226, "Ernest Hemingway", "Illinois, United States"},
+		{4, 100, "Andrea Hirata", "Bangka Belitung, Indonesia"},
+	}
+
+	values, _ := url.ParseQuery("country_id=100&city~=indonesia&sort=-author&limit=2")
+	options, err := Parse(values, &testSpec)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	page, result, err := Apply(options, items, valueFn)
+	if err != nil {
+		t.Fatalf("failed to apply query: %v", err)
+	}
+
+	if len(page) != 2 || page[0].ID != 2 || page[1].ID != 1 || result.Total != 3 || !result.HasNext {
+		t.Fatalf("invalid first page: got %v %+v", page, result)
+	}
+
+	values.Set("cursor", result.NextCursor)
+	options, err = Parse(values, &testSpec)
+	if err != nil {
+		t.Fatalf("failed to parse next cursor: %v", err)
+	}
+
+	page, result, err = Apply(options, items, valueFn)
+	if err != nil {
+		t.Fatalf("failed to apply query: %v", err)
+	}
+
+	if len(page) != 1 || page[0].ID != 4 || result.HasNext {
+		t.Fatalf("invalid next page: got %v %+v", page, result)
+	}
+}
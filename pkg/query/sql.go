@@ -0,0 +1,114 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// likeEscaper escapes the LIKE wildcards of user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Select appends the filters, cursor, sort order and limit to the base select
+// query. hasWhere reports whether base already ends with a WHERE clause. One
+// extra row is requested so that Paginate can tell whether a next page exists.
+func (o *Options) Select(base string, hasWhere bool) (string, []any) {
+	conditions, args := o.filters()
+
+	if o.Cursor != nil {
+		condition, cursorArgs := o.keyset()
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	var query strings.Builder
+	query.WriteString(where(base, hasWhere, conditions))
+
+	query.WriteString(" ORDER BY ")
+	for i, sort := range o.Sorts {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString(o.spec.Columns[sort.Field])
+		if sort.Desc {
+			query.WriteString(" DESC")
+		} else {
+			query.WriteString(" ASC")
+		}
+	}
+
+	query.WriteString(" LIMIT ?")
+	args = append(args, o.Limit+1)
+
+	if o.Cursor == nil && o.Offset > 0 {
+		query.WriteString(" OFFSET ?")
+		args = append(args, o.Offset)
+	}
+
+	return query.String(), args
+}
+
+// Count appends the filters to the base count query, the cursor is ignored
+// so that the total covers every page.
+func (o *Options) Count(base string, hasWhere bool) (string, []any) {
+	conditions, args := o.filters()
+	return where(base, hasWhere, conditions), args
+}
+
+func (o *Options) filters() ([]string, []any) {
+	var conditions []string
+	var args []any
+
+	for _, filter := range o.Filters {
+		column := o.spec.Columns[filter.Field]
+
+		switch filter.Operator {
+		case OpContains:
+			conditions = append(conditions, fmt.Sprintf("%s LIKE ?", column))
+			args = append(args, "%"+likeEscaper.Replace(filter.Value)+"%")
+		default:
+			conditions = append(conditions, fmt.Sprintf("%s %s ?", column, filter.Operator))
+			args = append(args, filter.Value)
+		}
+	}
+
+	return conditions, args
+}
+
+// keyset returns the condition selecting the rows after the cursor:
+// (s1 > v1) OR (s1 = v1 AND s2 > v2) OR ...
+func (o *Options) keyset() (string, []any) {
+	var or []string
+	var args []any
+
+	for i, sort := range o.Sorts {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%s = ?", o.spec.Columns[o.Sorts[j].Field]))
+			args = append(args, o.Cursor.Values[j])
+		}
+
+		operator := ">"
+		if sort.Desc {
+			operator = "<"
+		}
+		and = append(and, fmt.Sprintf("%s %s ?", o.spec.Columns[sort.Field], operator))
+		args = append(args, o.Cursor.Values[i])
+
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+func where(base string, hasWhere bool, conditions []string) string {
+	if len(conditions) == 0 {
+		return base
+	}
+
+	keyword := " WHERE "
+	if hasWhere {
+		keyword = " AND "
+	}
+
+	return base + keyword + strings.Join(conditions, " AND ")
+}
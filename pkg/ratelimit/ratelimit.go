@@ -0,0 +1,94 @@
+// Package ratelimit limits the rate of requests of clients with token
+// buckets, kept in a Store shared by the instances of the api or not.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit represents a token bucket refilled with Rate tokens per second up
+// to Burst tokens. A zero Rate means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns the limit of n requests per minute with the burst.
+func PerMinute(n float64, burst int) Limit {
+	return Limit{Rate: n / 60, Burst: burst}
+}
+
+// Unlimited reports whether the limit lets every request through.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result represents the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// RetryAfter is how long until the next token, when not allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store takes tokens from the buckets of the keys. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	// Peek tells whether Take would be allowed, without taking a token
+	Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket represents the state of a token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket for the time elapsed since its last take and
+// takes a token from it when there is a whole one.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	b.refill(limit, now)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return b.result(limit, allowed)
+}
+
+// peek refills the bucket and tells whether it holds a whole token, without
+// taking it.
+func (b *bucket) peek(limit Limit, now time.Time) Result {
+	b.refill(limit, now)
+	return b.result(limit, b.tokens >= 1)
+}
+
+// refill adds the tokens of the time elapsed since the last refill.
+func (b *bucket) refill(limit Limit, now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+}
+
+// result describes the bucket after a take allowed or not.
+func (b *bucket) result(limit Limit, allowed bool) Result {
+	result := Result{Allowed: allowed}
+	if !allowed {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	limit := PerMinute(60, 2)
+	now := time.Date(2026, time.October, 17, 9, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		after         time.Duration
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{0, "ip:10.0.0.1", true, 1, 0},
+		{0, "ip:10.0.0.1", true, 0, 0},
+		{0, "ip:10.0.0.1", false, 0, time.Second},
+		{0, "ip:10.0.0.2", true, 1, 0},
+		{500 * time.Millisecond, "ip:10.0.0.1", false, 0, 500 * time.Millisecond},
+		{500 * time.Millisecond, "ip:10.0.0.1", true, 0, 0},
+		{10 * time.Second, "ip:10.0.0.1", true, 1, 0},
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.after)
+		result, err := store.Take(context.Background(), step.key, limit, now)
+		if err != nil {
+			t.Fatalf("step %d: failed to take: %v", i, err)
+		}
+		if result.Allowed != step.wantAllowed || result.Remaining != step.wantRemaining || result.RetryAfter != step.wantRetry {
+			t.Fatalf("step %d: invalid result: got %+v, want allowed %v, remaining %d, retry after %s", i, result, step.wantAllowed, step.wantRemaining, step.wantRetry)
+		}
+	}
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	store := NewMemoryStore()
+	limit := PerMinute(60, 2)
+	now := time.Now()
+
+	store.Take(context.Background(), "ip:10.0.0.1", limit, now)
+	store.Take(context.Background(), "ip:10.0.0.2", limit, now.Add(2*time.Second))
+	store.sweep(now.Add(2 * time.Second))
+
+	if _, ok := store.buckets["ip:10.0.0.1"]; ok {
+		t.Fatalf("invalid sweep: refilled bucket kept")
+	}
+	if _, ok := store.buckets["ip:10.0.0.2"]; !ok {
+		t.Fatalf("invalid sweep: used bucket dropped")
+	}
+}
+
+func TestMemoryStore_Peek(t *testing.T) {
+	store := NewMemoryStore()
+	limit := PerMinute(60, 1)
+	now := time.Date(2026, time.October, 17, 9, 0, 0, 0, time.UTC)
+
+	if result, _ := store.Peek(context.Background(), "ip:10.0.0.1", limit, now); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("invalid peek of a missing bucket: got %+v", result)
+	}
+	if _, ok := store.buckets["ip:10.0.0.1"]; ok {
+		t.Fatalf("invalid peek: missing bucket created")
+	}
+
+	store.Take(context.Background(), "ip:10.0.0.1", limit, now)
+	for i := 0; i < 2; i++ {
+		if result, _ := store.Peek(context.Background(), "ip:10.0.0.1", limit, now); result.Allowed || result.RetryAfter != time.Second {
+			t.Fatalf("invalid peek %d of an empty bucket: got %+v", i, result)
+		}
+	}
+	if result, _ := store.Peek(context.Background(), "ip:10.0.0.1", limit, now.Add(time.Second)); !result.Allowed {
+		t.Fatalf("invalid peek of a refilled bucket: got %+v", result)
+	}
+}
@@ -0,0 +1,98 @@
+// Package schema verifies that the tables and columns the repositories query
+// exist in the connected MySQL database.
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Table lists the columns a repository expects in a table.
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+// Mismatch represents a table or column missing from the database.
+type Mismatch struct {
+	Table  string
+	Column string
+}
+
+func (m Mismatch) String() string {
+	if m.Column == "" {
+		return fmt.Sprintf("table %s is missing", m.Table)
+	}
+
+	return fmt.Sprintf("column %s.%s is missing", m.Table, m.Column)
+}
+
+// Error reports every mismatch found by Verify.
+type Error struct {
+	Mismatches []Mismatch
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("schema: database does not match the repositories, run the migrations:")
+	for _, mismatch := range e.Mismatches {
+		b.WriteString("\n  - ")
+		b.WriteString(mismatch.String())
+	}
+
+	return b.String()
+}
+
+// Verify compares the expected tables against information_schema for the
+// current database and returns an *Error listing every missing table and
+// column.
+func Verify(ctx context.Context, db *sql.DB, tables []Table) error {
+	query := "SELECT TABLE_NAME, COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE()"
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("schema: read information_schema: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("schema: read information_schema: %w", err)
+		}
+		actual[table] = append(actual[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("schema: read information_schema: %w", err)
+	}
+
+	return Compare(tables, actual)
+}
+
+// Compare checks the expected tables against the actual columns by table,
+// returning an *Error listing every missing table and column.
+func Compare(tables []Table, actual map[string][]string) error {
+	var mismatches []Mismatch
+	for _, table := range tables {
+		columns, ok := actual[table.Name]
+		if !ok {
+			mismatches = append(mismatches, Mismatch{Table: table.Name})
+			continue
+		}
+
+		for _, column := range table.Columns {
+			if !slices.Contains(columns, column) {
+				mismatches = append(mismatches, Mismatch{Table: table.Name, Column: column})
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return &Error{Mismatches: mismatches}
+	}
+
+	return nil
+}
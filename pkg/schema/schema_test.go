@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tables := []Table{
+		{Name: "author", Columns: []string{"id", "country_id", "author"}},
+		{Name: "country", Columns: []string{"id", "iso3"}},
+		{Name: "type", Columns: []string{"id", "type"}},
+	}
+	actual := map[string][]string{
+		"author":  {"id", "author", "city"},
+		"country": {"id", "iso3", "currency"},
+	}
+
+	err := Compare(tables, actual)
+
+	var schemaErr *Error
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("invalid error: got %v, want *Error", err)
+	}
+
+	expected := []Mismatch{
+		{Table: "author", Column: "country_id"},
+		{Table: "type"},
+	}
+	if !reflect.DeepEqual(schemaErr.Mismatches, expected) {
+		t.Fatalf("invalid mismatches: got %+v, want %+v", schemaErr.Mismatches, expected)
+	}
+
+	actual["author"] = append(actual["author"], "country_id")
+	actual["type"] = []string{"id", "type"}
+	if err := Compare(tables, actual); err != nil {
+		t.Fatalf("invalid error for a matching schema: got %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+// Package memory provides an in-memory database for the repositories, with
+// auto increment ids, foreign key checks and serialized transactions, so the
+// application can run without MySQL.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/store"
+)
+
+// A set of errors returned by the in-memory database.
+var (
+	ErrNoTable    = errors.New("memory: table does not exist")
+	ErrForeignKey = errors.New("memory: foreign key constraint fails")
+	ErrRestrict   = errors.New("memory: row is still referenced")
+	ErrNotFound   = errors.New("memory: row not found")
+	ErrReadOnly   = errors.New("memory: transaction is read only")
+)
+
+// RefsFn returns the rows referenced by a row, as table name to id. A zero
+// id represents a NULL reference.
+type RefsFn func(row any) map[string]uint64
+
+// DB represents an in-memory database of tables keyed by id.
+type DB struct {
+	mu     sync.RWMutex
+	tables map[string]*table
+}
+
+type table struct {
+	rows   map[uint64]any
+	nextID uint64
+	refs   RefsFn
+}
+
+func (t *table) clone() *table {
+	return &table{
+		rows:   maps.Clone(t.rows),
+		nextID: t.nextID,
+		refs:   t.refs,
+	}
+}
+
+// New constructs an empty in-memory database.
+func New() *DB {
+	return &DB{
+		tables: make(map[string]*table),
+	}
+}
+
+// CreateTable adds a table whose rows reference other tables through refs,
+// which may be nil.
+func (db *DB) CreateTable(name string, refs RefsFn) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.tables[name] = &table{
+		rows: make(map[uint64]any),
+		refs: refs,
+	}
+}
+
+// BeginTx starts a transaction. Read only transactions run concurrently,
+// others hold the database exclusively until they end and work on a copy of
+// the tables that replaces the database tables on commit.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (store.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tx := Tx{db: db}
+	if opts != nil && opts.ReadOnly {
+		db.mu.RLock()
+		tx.readOnly = true
+		tx.tables = db.tables
+		return &tx, nil
+	}
+
+	db.mu.Lock()
+	tx.tables = make(map[string]*table, len(db.tables))
+	for name, t := range db.tables {
+		tx.tables[name] = t.clone()
+	}
+
+	return &tx, nil
+}
+
+// Tx represents an in-memory transaction, or a savepoint within one.
+type Tx struct {
+	db       *DB
+	readOnly bool
+	done     bool
+	tables   map[string]*table
+
+	// saved holds the tables as they were when the savepoint started
+	saved map[string]*table
+}
+
+// Of returns the in-memory transaction behind tx. It panics when tx was not
+// started by a DB, which is a wiring mistake.
+func Of(tx store.Tx) *Tx {
+	memoryTx, ok := tx.(*Tx)
+	if !ok {
+		panic(fmt.Sprintf("memory: %T is not an in-memory transaction", tx))
+	}
+
+	return memoryTx
+}
+
+// BeginTx starts a savepoint within the transaction, opts are ignored since
+// a savepoint shares the options of its transaction.
+func (tx *Tx) BeginTx(ctx context.Context, opts *sql.TxOptions) (store.Tx, error) {
+	if tx.done {
+		return nil, sql.ErrTxDone
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	saved := make(map[string]*table, len(tx.tables))
+	for name, t := range tx.tables {
+		saved[name] = t.clone()
+	}
+
+	return &Tx{db: tx.db, readOnly: tx.readOnly, tables: tx.tables, saved: saved}, nil
+}
+
+// Commit makes the changes of the transaction visible, or releases the
+// savepoint.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	if tx.saved != nil {
+		return nil
+	}
+
+	if tx.readOnly {
+		tx.db.mu.RUnlock()
+		return nil
+	}
+
+	tx.db.tables = tx.tables
+	tx.db.mu.Unlock()
+	return nil
+}
+
+// Rollback discards the changes of the transaction, or only the changes made
+// since the savepoint.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	if tx.saved != nil {
+		maps.Copy(tx.tables, tx.saved)
+		return nil
+	}
+
+	if tx.readOnly {
+		tx.db.mu.RUnlock()
+		return nil
+	}
+
+	tx.db.mu.Unlock()
+	return nil
+}
+
+// Get returns the row of the table with the id.
+func (tx *Tx) Get(name string, id uint64) (any, bool, error) {
+	t, err := tx.table(name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	row, ok := t.rows[id]
+	return row, ok, nil
+}
+
+// Rows returns every row of the table ordered by id.
+func (tx *Tx) Rows(name string) ([]any, error) {
+	t, err := tx.table(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := slices.Sorted(maps.Keys(t.rows))
+	rows := make([]any, len(ids))
+	for i, id := range ids {
+		rows[i] = t.rows[id]
+	}
+
+	return rows, nil
+}
+
+// Insert adds the row built by newRow for the next auto increment id of the
+// table and returns that id.
+func (tx *Tx) Insert(name string, newRow func(id uint64) any) (uint64, error) {
+	t, err := tx.writableTable(name)
+	if err != nil {
+		return 0, err
+	}
+
+	id := t.nextID + 1
+	if err := tx.Put(name, id, newRow(id)); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Put inserts or replaces the row with the id, checking the rows it references.
+func (tx *Tx) Put(name string, id uint64, row any) error {
+	t, err := tx.writableTable(name)
+	if err != nil {
+		return err
+	}
+
+	if t.refs != nil {
+		for refName, refID := range t.refs(row) {
+			if refID == 0 {
+				continue
+			}
+
+			ref, err := tx.table(refName)
+			if err != nil {
+				return err
+			}
+			if _, ok := ref.rows[refID]; !ok {
+				err := fmt.Errorf("%w: %s references %s %d", ErrForeignKey, name, refName, refID)
+				return errs.Wrap(errs.KindFKViolation, store.MessageNoReferencedRow, err)
+			}
+		}
+	}
+
+	t.rows[id] = row
+	t.nextID = max(t.nextID, id)
+	return nil
+}
+
+// Delete removes the row with the id unless other rows still reference it.
+func (tx *Tx) Delete(name string, id uint64) error {
+	t, err := tx.writableTable(name)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := t.rows[id]; !ok {
+		return errs.Wrap(errs.KindNotFound, store.MessageNotFound, fmt.Errorf("%w: %s %d", ErrNotFound, name, id))
+	}
+
+	for otherName, other := range tx.tables {
+		if other.refs == nil {
+			continue
+		}
+
+		for _, row := range other.rows {
+			if other.refs(row)[name] == id {
+				err := fmt.Errorf("%w: %s %d by %s", ErrRestrict, name, id, otherName)
+				return errs.Wrap(errs.KindConflict, store.MessageRowIsReferenced, err)
+			}
+		}
+	}
+
+	delete(t.rows, id)
+	return nil
+}
+
+// CountReferences counts the rows of the from table referencing the row of
+// the table with the id.
+func (tx *Tx) CountReferences(name string, id uint64, from string) (int, error) {
+	t, err := tx.table(from)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	if t.refs == nil {
+		return count, nil
+	}
+
+	for _, row := range t.rows {
+		if t.refs(row)[name] == id {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (tx *Tx) table(name string) (*table, error) {
+	if tx.done {
+		return nil, sql.ErrTxDone
+	}
+
+	t, ok := tx.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoTable, name)
+	}
+
+	return t, nil
+}
+
+func (tx *Tx) writableTable(name string) (*table, error) {
+	if tx.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	return tx.table(name)
+}
+
+// Get returns the row of the table with the id as a T.
+func Get[T any](tx *Tx, name string, id uint64) (T, bool, error) {
+	var zero T
+
+	row, ok, err := tx.Get(name, id)
+	if err != nil || !ok {
+		return zero, false, err
+	}
+
+	typed, ok := row.(T)
+	if !ok {
+		return zero, false, fmt.Errorf("memory: %s row is %T", name, row)
+	}
+
+	return typed, true, nil
+}
+
+// Rows returns every row of the table ordered by id as a T.
+func Rows[T any](tx *Tx, name string) ([]T, error) {
+	rows, err := tx.Rows(name)
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]T, 0, len(rows))
+	for _, row := range rows {
+		value, ok := row.(T)
+		if !ok {
+			return nil, fmt.Errorf("memory: %s row is %T", name, row)
+		}
+		typed = append(typed, value)
+	}
+
+	return typed, nil
+}
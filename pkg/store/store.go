@@ -0,0 +1,97 @@
+// Package store defines the transaction abstractions shared by the
+// repositories, so that usecases do not depend on a concrete database.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Tx represents a database transaction, whose BeginTx starts a nested
+// transaction as a savepoint.
+type Tx interface {
+	Beginner
+	Commit() error
+	Rollback() error
+}
+
+// Beginner starts transactions.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
+// ReadOnly are the options of a transaction that only reads.
+var ReadOnly = &sql.TxOptions{ReadOnly: true}
+
+// SQLBeginner starts transactions on a database/sql connection pool.
+type SQLBeginner struct {
+	DB *sql.DB
+}
+
+// NewSQLBeginner constructs a Beginner for the connection pool.
+func NewSQLBeginner(db *sql.DB) SQLBeginner {
+	return SQLBeginner{DB: db}
+}
+
+// BeginTx starts a database/sql transaction, which rolls back when the
+// context is done.
+func (b SQLBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := b.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLTx{Tx: tx}, nil
+}
+
+// SQLTx represents a database/sql transaction, or a savepoint within one.
+type SQLTx struct {
+	Tx        *sql.Tx
+	ctx       context.Context
+	savepoint string
+	depth     int
+}
+
+// Commit commits the transaction, or releases the savepoint.
+func (t *SQLTx) Commit() error {
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+
+	_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
+
+// Rollback rolls the transaction back, or only the changes made since the
+// savepoint.
+func (t *SQLTx) Rollback() error {
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+
+	_, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO SAVEPOINT "+t.savepoint)
+	return err
+}
+
+// BeginTx starts a savepoint within the transaction, opts are ignored since
+// a savepoint shares the options of its transaction.
+func (t *SQLTx) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	savepoint := fmt.Sprintf("sp_%d", t.depth+1)
+	if _, err := t.Tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return nil, err
+	}
+
+	return &SQLTx{Tx: t.Tx, ctx: ctx, savepoint: savepoint, depth: t.depth + 1}, nil
+}
+
+// SQL returns the database/sql transaction behind tx. It panics when tx was
+// not started by a SQLBeginner, which is a wiring mistake.
+func SQL(tx Tx) *sql.Tx {
+	sqlTx, ok := tx.(*SQLTx)
+	if !ok {
+		panic(fmt.Sprintf("store: %T is not a database/sql transaction", tx))
+	}
+
+	return sqlTx.Tx
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"toko-buku-api/pkg/errs"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers of a transaction that may succeed when retried.
+const (
+	mysqlLockWaitTimeout = 1205
+	mysqlDeadlock        = 1213
+)
+
+// MySQL error numbers of constraint violations.
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+)
+
+// Client messages of constraint violations, shared by every store so the
+// api answers the same whichever database runs.
+const (
+	MessageNotFound        = "resource not found"
+	MessageDuplicateEntry  = "resource already exists"
+	MessageRowIsReferenced = "resource is still referenced"
+	MessageNoReferencedRow = "referenced resource does not exist"
+)
+
+// MaxRetries bounds how many times WithTx retries a transaction that hit a
+// deadlock or a lock wait timeout.
+const MaxRetries = 3
+
+// retryBackoff is the wait before the first retry, doubled on every retry.
+const retryBackoff = 20 * time.Millisecond
+
+// WithTx runs fn as a unit of work in a transaction started by b. The
+// transaction commits when fn returns nil and rolls back when fn returns an
+// error or panics. Passing the Tx of an outer unit of work as b nests fn in a
+// savepoint. Outermost transactions are retried from the start on a MySQL
+// deadlock or lock wait timeout, so fn must not keep state across calls.
+// MySQL constraint violations are returned as errs errors.
+func WithTx(ctx context.Context, b Beginner, opts *sql.TxOptions, fn func(tx Tx) error) error {
+	_, nested := b.(Tx)
+
+	backoff := retryBackoff
+	for retries := 0; ; retries++ {
+		err := run(ctx, b, opts, fn)
+		if nested || retries == MaxRetries || !retryable(err) {
+			return classify(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+func run(ctx context.Context, b Beginner, opts *sql.TxOptions, fn func(tx Tx) error) error {
+	tx, err := b.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// classify wraps a MySQL constraint violation in the matching errs error,
+// errors already classified are returned as is.
+func classify(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errs.KindOf(err) != errs.KindInternal || !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case mysqlDuplicateEntry:
+		return errs.Wrap(errs.KindConflict, MessageDuplicateEntry, err)
+	case mysqlRowIsReferenced:
+		return errs.Wrap(errs.KindConflict, MessageRowIsReferenced, err)
+	case mysqlNoReferencedRow:
+		return errs.Wrap(errs.KindFKViolation, MessageNoReferencedRow, err)
+	}
+
+	return err
+}
+
+// retryable reports whether err is a MySQL deadlock or lock wait timeout.
+func retryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	return mysqlErr.Number == mysqlDeadlock || mysqlErr.Number == mysqlLockWaitTimeout
+}
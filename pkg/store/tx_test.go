@@ -0,0 +1,199 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/pkg/store"
+	"toko-buku-api/pkg/store/memory"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type fakeTx struct {
+	commits, rollbacks int
+}
+
+func (tx *fakeTx) Commit() error   { tx.commits++; return nil }
+func (tx *fakeTx) Rollback() error { tx.rollbacks++; return nil }
+
+func (tx *fakeTx) BeginTx(ctx context.Context, opts *sql.TxOptions) (store.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+type fakeBeginner struct {
+	txs []*fakeTx
+}
+
+func (b *fakeBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (store.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tx := &fakeTx{}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+func TestWithTx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	testCases := []struct {
+		name          string
+		fn            func(attempt int) error
+		wantErr       error
+		wantTxs       int
+		wantCommitted bool
+	}{
+		{
+			name:          "commit",
+			fn:            func(int) error { return nil },
+			wantTxs:       1,
+			wantCommitted: true,
+		},
+		{
+			name:    "rollback on error",
+			fn:      func(int) error { return errFailed },
+			wantErr: errFailed,
+			wantTxs: 1,
+		},
+		{
+			name: "retry on deadlock",
+			fn: func(attempt int) error {
+				if attempt < 2 {
+					return &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+				}
+				return nil
+			},
+			wantTxs:       3,
+			wantCommitted: true,
+		},
+		{
+			name: "give up on lock wait timeout",
+			fn: func(int) error {
+				return &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+			},
+			wantTxs: store.MaxRetries + 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			beginner := &fakeBeginner{}
+			attempt := 0
+
+			err := store.WithTx(context.Background(), beginner, nil, func(tx store.Tx) error {
+				defer func() { attempt++ }()
+				return tc.fn(attempt)
+			})
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("invalid error: got %v, want %v", err, tc.wantErr)
+			}
+
+			if len(beginner.txs) != tc.wantTxs {
+				t.Fatalf("invalid transactions: got %d, want %d", len(beginner.txs), tc.wantTxs)
+			}
+
+			last := beginner.txs[len(beginner.txs)-1]
+			if committed := last.commits == 1 && last.rollbacks == 0; committed != tc.wantCommitted {
+				t.Fatalf("invalid last transaction: got %+v, want committed %v", last, tc.wantCommitted)
+			}
+		})
+	}
+}
+
+func TestWithTx_panic(t *testing.T) {
+	beginner := &fakeBeginner{}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("invalid recover: got nil, want the panic")
+		}
+		if tx := beginner.txs[0]; tx.rollbacks != 1 || tx.commits != 0 {
+			t.Fatalf("invalid transaction after panic: got %+v", tx)
+		}
+	}()
+
+	store.WithTx(context.Background(), beginner, nil, func(tx store.Tx) error {
+		panic("boom")
+	})
+}
+
+func TestWithTx_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := store.WithTx(ctx, &fakeBeginner{}, nil, func(tx store.Tx) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("invalid error: got %v, want context.Canceled", err)
+	}
+}
+
+func TestWithTx_classify(t *testing.T) {
+	testCases := []struct {
+		number   uint16
+		wantKind errs.Kind
+	}{
+		{1062, errs.KindConflict},
+		{1451, errs.KindConflict},
+		{1452, errs.KindFKViolation},
+		{1146, errs.KindInternal},
+	}
+
+	for _, tc := range testCases {
+		mysqlErr := &mysql.MySQLError{Number: tc.number}
+		err := store.WithTx(context.Background(), &fakeBeginner{}, nil, func(tx store.Tx) error {
+			return mysqlErr
+		})
+		if kind := errs.KindOf(err); kind != tc.wantKind {
+			t.Fatalf("invalid kind of %d: got %s, want %s", tc.number, kind, tc.wantKind)
+		}
+		if !errors.Is(err, mysqlErr) {
+			t.Fatalf("invalid error of %d: cause not found in %v", tc.number, err)
+		}
+	}
+}
+
+func TestWithTx_savepoint(t *testing.T) {
+	db := memory.New()
+	db.CreateTable("type", nil)
+	errFailed := errors.New("failed")
+
+	err := store.WithTx(context.Background(), db, nil, func(tx store.Tx) error {
+		if _, err := memory.Of(tx).Insert("type", func(uint64) any { return "novel" }); err != nil {
+			return err
+		}
+
+		err := store.WithTx(context.Background(), tx, nil, func(savepoint store.Tx) error {
+			if _, err := memory.Of(savepoint).Insert("type", func(uint64) any { return "poetry" }); err != nil {
+				return err
+			}
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("invalid savepoint error: got %v, want %v", err, errFailed)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to run the unit of work: %v", err)
+	}
+
+	err = store.WithTx(context.Background(), db, store.ReadOnly, func(tx store.Tx) error {
+		rows, err := memory.Rows[string](memory.Of(tx), "type")
+		if err != nil {
+			return err
+		}
+
+		if len(rows) != 1 || rows[0] != "novel" {
+			t.Fatalf("invalid rows after savepoint rollback: got %v", rows)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read rows: %v", err)
+	}
+}
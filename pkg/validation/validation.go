@@ -0,0 +1,184 @@
+// Package validation builds the request validator, naming fields after their
+// json tags, and translates its errors to the languages of the api.
+package validation
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	idtranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+// Supported languages, English is the fallback.
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+)
+
+var universal = ut.New(en.New(), en.New(), id.New())
+
+// invalidMessages are used for tags without a translation.
+var invalidMessages = map[string]string{
+	LanguageEnglish:    "{0} is invalid",
+	LanguageIndonesian: "{0} tidak valid",
+}
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+	errNew   error
+)
+
+// Default returns the validator shared by the api, naming fields after their
+// json tags, with the messages of every supported language registered. The
+// messages live in one translator per language, so the validator is built
+// once.
+func Default() (*validator.Validate, error) {
+	once.Do(func() {
+		validate, errNew = newValidate()
+	})
+
+	return validate, errNew
+}
+
+func newValidate() (*validator.Validate, error) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonName)
+
+	enTrans, _ := universal.GetTranslator(LanguageEnglish)
+	if err := entranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, err
+	}
+
+	idTrans, _ := universal.GetTranslator(LanguageIndonesian)
+	if err := idtranslations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		return nil, err
+	}
+
+	err := RegisterTranslation(validate, "unique", map[string]string{
+		LanguageIndonesian: "{0} harus berisi nilai yang unik",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for language, message := range invalidMessages {
+		trans, _ := universal.GetTranslator(language)
+		if err := trans.Add("invalid", message, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return validate, nil
+}
+
+// RegisterTranslation registers the message of a tag per language, where
+// {0} is the field name and {1} the tag parameter. Registering a tag again
+// replaces its messages.
+func RegisterTranslation(validate *validator.Validate, tag string, messages map[string]string) error {
+	for language, message := range messages {
+		trans, _ := universal.GetTranslator(language)
+
+		register := func(trans ut.Translator) error {
+			return trans.Add(tag, message, true)
+		}
+		translate := func(trans ut.Translator, fieldErr validator.FieldError) string {
+			message, err := trans.T(tag, fieldErr.Field(), fieldErr.Param())
+			if err != nil {
+				return fieldErr.Error()
+			}
+			return message
+		}
+
+		if err := validate.RegisterTranslation(tag, trans, register, translate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Translator returns the translator of the most preferred supported language
+// of an Accept-Language header, or English.
+func Translator(acceptLanguage string) ut.Translator {
+	trans, _ := universal.FindTranslator(languages(acceptLanguage)...)
+	return trans
+}
+
+// Translate returns the message of the failed validation in the language of
+// trans.
+func Translate(trans ut.Translator, fieldErr validator.FieldError) string {
+	message := fieldErr.Translate(trans)
+	if message != fieldErr.Error() {
+		return message
+	}
+
+	message, err := trans.T("invalid", fieldErr.Field())
+	if err != nil {
+		return fieldErr.Error()
+	}
+	return message
+}
+
+// languages lists the primary language subtags of an Accept-Language
+// header, most preferred first.
+func languages(acceptLanguage string) []string {
+	type weighted struct {
+		language string
+		q        float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		primary, _, _ := strings.Cut(tag, "-")
+		ranges = append(ranges, weighted{strings.ToLower(primary), q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	languages := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r.q > 0 {
+			languages = append(languages, r.language)
+		}
+	}
+
+	return languages
+}
+
+// jsonName names a field after its json tag, or its Go name without one.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+
+	return name
+}
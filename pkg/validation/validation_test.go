@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestLanguages(t *testing.T) {
+	testCases := []struct {
+		acceptLanguage string
+		want           []string
+	}{
+		{"", []string{}},
+		{"id", []string{"id"}},
+		{"id-ID,id;q=0.9,en;q=0.8", []string{"id", "id", "en"}},
+		{"en;q=0.5, id-ID", []string{"id", "en"}},
+		{"fr;q=0, *;q=0.1, EN-us", []string{"en"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			if got := languages(tc.acceptLanguage); !slices.Equal(got, tc.want) {
+				t.Fatalf("invalid languages: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	validate, err := Default()
+	if err != nil {
+		t.Fatalf("failed to build validator: %v", err)
+	}
+
+	request := struct {
+		Nice_Country string `validate:"required" json:"nice_country"`
+		Iso3         string `validate:"min=3,max=3" json:"iso3"`
+	}{Iso3: "id"}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(validate.Struct(request), &validationErrors) {
+		t.Fatalf("invalid error: want validator.ValidationErrors")
+	}
+
+	testCases := []struct {
+		acceptLanguage string
+		want           []string
+	}{
+		{"en", []string{"nice_country is a required field", "iso3 must be at least 3 characters in length"}},
+		{"id-ID,id;q=0.9", []string{"nice_country wajib diisi", "panjang minimal iso3 adalah 3 karakter"}},
+		{"fr", []string{"nice_country is a required field", "iso3 must be at least 3 characters in length"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			trans := Translator(tc.acceptLanguage)
+
+			var got []string
+			for _, fieldErr := range validationErrors {
+				got = append(got, Translate(trans, fieldErr))
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("invalid messages: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
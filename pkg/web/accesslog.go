@@ -0,0 +1,56 @@
+package web
+
+import (
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+	"toko-buku-api/pkg/logger"
+)
+
+// AccessLog logs one line per request with its route pattern, status code,
+// latency, response size, client ip and user agent. Requests answered with
+// a status below 400 are logged at the sampleRate, from 0 to 1, other ones
+// always are.
+func AccessLog(log *logger.Logger, sampleRate float64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := newResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.Status()
+			if status < http.StatusBadRequest && rand.Float64() >= sampleRate {
+				return
+			}
+
+			args := []any{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", r.Pattern,
+				"status", status,
+				"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+				"bytes", rw.bytes,
+				"ip", ClientIP(r),
+				"user_agent", r.UserAgent(),
+			}
+
+			if status >= http.StatusInternalServerError {
+				log.Error(r.Context(), "request completed", args...)
+				return
+			}
+			log.Info(r.Context(), "request completed", args...)
+		})
+	}
+}
+
+// ClientIP returns the ip address of the client connection.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
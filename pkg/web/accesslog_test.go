@@ -0,0 +1,72 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toko-buku-api/pkg/logger"
+)
+
+func TestAccessLog(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		sampleRate float64
+		wantLogged bool
+	}{
+		{"sampled success", http.StatusOK, 1, true},
+		{"skipped success", http.StatusOK, 0, false},
+		{"client error", http.StatusNotFound, 0, true},
+		{"server error", http.StatusInternalServerError, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LevelDebug, "TEST", GetTraceID)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /books/{bookById}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte("hello"))
+			})
+			handler := Wrap(mux, Trace, AccessLog(log, tc.sampleRate))
+
+			request := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+			request.Header.Set("User-Agent", "test-agent")
+			request.Header.Set(HeaderRequestID, "req-42")
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			if !tc.wantLogged {
+				if buf.Len() != 0 {
+					t.Fatalf("invalid log: got %s, want none", buf.String())
+				}
+				return
+			}
+
+			var line map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+				t.Fatalf("failed to decode log line %q: %v", buf.String(), err)
+			}
+
+			want := map[string]any{
+				"route":      "GET /books/{bookById}",
+				"status":     float64(tc.status),
+				"bytes":      float64(5),
+				"ip":         "192.0.2.1",
+				"user_agent": "test-agent",
+				"trace_id":   "req-42",
+			}
+			for key, value := range want {
+				if line[key] != value {
+					t.Fatalf("invalid %s: got %v, want %v", key, line[key], value)
+				}
+			}
+			if _, ok := line["latency_ms"]; !ok {
+				t.Fatalf("invalid log line: want latency_ms in %v", line)
+			}
+		})
+	}
+}
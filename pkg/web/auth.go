@@ -0,0 +1,123 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+	"toko-buku-api/utils"
+)
+
+// Authenticator returns the claims of the credentials a request carries, ok
+// false when it carries none.
+type Authenticator func(r *http.Request) (claims auth.Claims, ok bool, err error)
+
+// Bearer authenticates the JWT of the Authorization header.
+func Bearer(verifier *auth.JWT) Authenticator {
+	return func(r *http.Request) (auth.Claims, bool, error) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			return auth.Claims{}, false, nil
+		}
+
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return auth.Claims{}, true, errs.Unauthorized("invalid authorization header")
+		}
+
+		claims, err := verifier.Verify(strings.TrimSpace(token))
+		return claims, true, err
+	}
+}
+
+// HeaderAPIKey is the header carrying the API keys of machine clients.
+const HeaderAPIKey = "X-API-Key"
+
+// APIKey authenticates the API key of the X-API-Key header.
+func APIKey(verifier auth.KeyVerifier) Authenticator {
+	return func(r *http.Request) (auth.Claims, bool, error) {
+		key := r.Header.Get(HeaderAPIKey)
+		if key == "" {
+			return auth.Claims{}, false, nil
+		}
+
+		claims, err := verifier.VerifyKey(r.Context(), key)
+		return claims, true, err
+	}
+}
+
+// Authenticate stores the claims of the first authenticator finding
+// credentials in the context of the request. Invalid credentials are
+// answered with 401, and credentials the authenticator failed to check, an
+// internal error, with 500; requests without any go on anonymous.
+func Authenticate(authenticators ...Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticate := range authenticators {
+				claims, ok, err := authenticate(r)
+				if !ok {
+					continue
+				}
+				if err != nil && errs.KindOf(err) == errs.KindInternal {
+					response := utils.StatusInternalServerError()
+					RespondError(w, r, http.StatusInternalServerError, response.Message, response)
+					return
+				}
+				if err != nil {
+					unauthorized(w, r, errs.MessageOf(err))
+					return
+				}
+
+				// the mux sets the route pattern on the request it is given,
+				// which the access log reads from the outer one
+				authenticated := r.WithContext(setClaims(r.Context(), claims))
+				next.ServeHTTP(w, authenticated)
+				r.Pattern = authenticated.Pattern
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authorize answers anonymous requests with 401, and requests of principals
+// the policy does not grant the permission with 403.
+func Authorize(policy auth.Policy, permission auth.Permission) Middleware {
+	return AuthorizeAny(policy, permission)
+}
+
+// AuthorizeAny is Authorize for routes any of the permissions opens, which
+// leave the handler to tell what the principal may see.
+func AuthorizeAny(policy auth.Policy, permissions ...auth.Permission) Middleware {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	message := fmt.Sprintf("%s permission required", strings.Join(names, " or "))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				unauthorized(w, r, "authentication required")
+				return
+			}
+			if !slices.ContainsFunc(permissions, func(permission auth.Permission) bool {
+				return policy.Allows(claims, permission)
+			}) {
+				RespondError(w, r, http.StatusForbidden, message, utils.StatusForbidden(message))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	RespondError(w, r, http.StatusUnauthorized, message, utils.StatusUnauthorized(message))
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/errs"
+)
+
+func TestAuthenticate(t *testing.T) {
+	jwt, err := auth.NewHS256([]byte("toko-buku-api-test-secret-0123456789"), "toko-buku-api", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to build HS256: %v", err)
+	}
+	sign := func(role string) string {
+		token, err := jwt.Sign(auth.Claims{Subject: role, Role: role})
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return token
+	}
+	policy := auth.Policy{auth.RoleAdmin: {auth.CountriesWrite}}
+
+	testCases := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantSubject   string
+	}{
+		{"bearer", "Bearer " + sign(auth.RoleAdmin), http.StatusOK, auth.RoleAdmin},
+		{"without permission", "Bearer " + sign(auth.RoleStaff), http.StatusForbidden, ""},
+		{"anonymous", "", http.StatusUnauthorized, ""},
+		{"invalid token", "Bearer " + sign(auth.RoleAdmin) + "x", http.StatusUnauthorized, ""},
+		{"other scheme", "Basic c3RhZmY=", http.StatusUnauthorized, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var subject string
+			handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, _ := GetClaims(r.Context())
+				subject = claims.Subject
+			}), Authenticate(Bearer(jwt)), Authorize(policy, auth.CountriesWrite))
+
+			request := httptest.NewRequest(http.MethodPost, "/countries", nil)
+			if tc.authorization != "" {
+				request.Header.Set("Authorization", tc.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.wantStatus || subject != tc.wantSubject {
+				t.Fatalf("invalid response: got %d %q, want %d %q", recorder.Code, subject, tc.wantStatus, tc.wantSubject)
+			}
+			if tc.wantStatus == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Fatalf("invalid WWW-Authenticate: got %q", recorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+type fakeKeyVerifier map[string]auth.Claims
+
+func (v fakeKeyVerifier) VerifyKey(ctx context.Context, key string) (auth.Claims, error) {
+	if key == "tbk_down_secret" {
+		return auth.Claims{}, errs.Internal(errors.New("database is down"))
+	}
+	claims, ok := v[key]
+	if !ok {
+		return auth.Claims{}, errs.Unauthorized("invalid api key")
+	}
+	return claims, nil
+}
+
+func TestAPIKey(t *testing.T) {
+	verifier := fakeKeyVerifier{"tbk_1234_secret": {Subject: "apikey:1234", Scopes: []string{"countries:write"}}}
+	policy := auth.Policy{}
+
+	testCases := []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{"scoped key", "tbk_1234_secret", http.StatusOK},
+		{"unknown key", "tbk_1234_wrong", http.StatusUnauthorized},
+		{"failing verifier", "tbk_down_secret", http.StatusInternalServerError},
+		{"no key", "", http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+				Authenticate(APIKey(verifier)), Authorize(policy, auth.CountriesWrite))
+
+			request := httptest.NewRequest(http.MethodPost, "/countries", nil)
+			if tc.key != "" {
+				request.Header.Set(HeaderAPIKey, tc.key)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.wantStatus {
+				t.Fatalf("invalid status: got %d, want %d", recorder.Code, tc.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package web
+
+import "net/http"
+
+// LimitBody limits the request bodies to maxBytes, reading past it failing
+// with an *http.MaxBytesError and closing the connection once answered. A
+// maxBytes of zero or less leaves them unlimited.
+func LimitBody(maxBytes int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBytes > 0 && r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package web
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"toko-buku-api/pkg/etag"
+)
+
+// CompressOptions sets which responses Compress encodes, and how hard.
+type CompressOptions struct {
+	// MinSize is the size, in bytes, below which bodies are sent as they are.
+	MinSize int
+	// Level is a compress/flate level, from flate.HuffmanOnly to
+	// flate.BestCompression.
+	Level int
+}
+
+// incompressibleTypes are the content types already compressed, or the
+// prefixes of them ending with a slash.
+var incompressibleTypes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"font/woff",
+	"font/woff2",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/zstd",
+	"application/x-7z-compressed",
+	"application/x-bzip2",
+	"application/x-rar-compressed",
+	"application/pdf",
+}
+
+// encoder compresses a response body into the writer it was reset to.
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// Compress encodes the responses of at least options.MinSize bytes with
+// gzip or deflate, whichever the Accept-Encoding header of the request
+// prefers, gzip on ties. Bodies already encoded or of compressed content
+// types are sent as they are.
+func Compress(options CompressOptions) Middleware {
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, options.Level)
+			return w
+		}},
+		"deflate": {New: func() any {
+			w, _ := flate.NewWriter(io.Discard, options.Level)
+			return w
+		}},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, pool: pools[encoding], minSize: options.MinSize}
+			next.ServeHTTP(cw, r)
+			cw.close()
+		})
+	}
+}
+
+// negotiateEncoding returns gzip or deflate, whichever acceptEncoding gives
+// the highest quality, or an empty string when it accepts neither.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					q = 0
+				}
+				quality = q
+			}
+		}
+		qualities[coding] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, coding := range []string{"gzip", "deflate"} {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+
+	return best
+}
+
+// compressWriter holds the status line and the start of the body back until
+// minSize bytes are written, or the handler returns, to know whether to
+// encode them.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	minSize  int
+
+	status  int
+	buf     []byte
+	decided bool
+	encoder encoder
+}
+
+// WriteHeader holds the status code back until the body is known to be
+// encoded or not.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write encodes b once the body reaches minSize bytes, and holds it back
+// until then.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) < w.minSize && w.compressible() {
+		return len(b), nil
+	}
+	if err := w.decide(w.compressible()); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// Flush sends the body held back, encoded when compressible, then flushes
+// the encoder and the connection.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.decide(len(w.buf) > 0 && w.compressible())
+	}
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible tells whether the response, as written so far, may be
+// encoded.
+func (w *compressWriter) compressible() bool {
+	switch {
+	case w.status == http.StatusNoContent || w.status == http.StatusNotModified:
+		return false
+	case w.Header().Get("Content-Encoding") != "":
+		return false
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, incompressible := range incompressibleTypes {
+		if mediaType == incompressible || strings.HasSuffix(incompressible, "/") && strings.HasPrefix(mediaType, incompressible) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decide writes the status line and the body held back, through a pooled
+// encoder when compress is set.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	if compress {
+		header := w.Header()
+		// sniff the content type from the body, not from its encoding
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// the encoded body is another representation, with its own tag
+		if tag := header.Get("ETag"); tag != "" {
+			header.Set("ETag", etag.Encoded(tag, w.encoding))
+		}
+
+		w.encoder = w.pool.Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+// close sends what is still held back, uncompressed as it is below
+// minSize, or ends the encoded body and puts the encoder back in the pool.
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.decide(false)
+		return
+	}
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		w.pool.Put(w.encoder)
+		w.encoder = nil
+	}
+}
@@ -0,0 +1,147 @@
+package web
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"toko-buku-api/utils"
+)
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("toko buku ", 200)
+	handler := Compress(CompressOptions{MinSize: 1024, Level: flate.DefaultCompression})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			utils.RespondWithJSON(w, http.StatusCreated, utils.StatusOK(large))
+		case "/small":
+			utils.RespondWithJSON(w, http.StatusOK, utils.StatusOK("toko buku"))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte(large))
+		case "/encoded":
+			w.Header().Set("Content-Encoding", "br")
+			w.Write([]byte(large))
+		}
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{"gzip", "/large", "gzip, deflate", "gzip"},
+		{"deflate preferred", "/large", "gzip;q=0.5, deflate", "deflate"},
+		{"any", "/large", "*", "gzip"},
+		{"refused", "/large", "gzip;q=0, br", ""},
+		{"none", "/large", "", ""},
+		{"below min size", "/small", "gzip", ""},
+		{"compressed type", "/image", "gzip", ""},
+		{"already encoded", "/encoded", "gzip", "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if got := recorder.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("invalid Content-Encoding: got %q, want %q", got, tt.wantEncoding)
+			}
+			if got := recorder.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Fatalf("invalid Vary: got %q, want Accept-Encoding", got)
+			}
+
+			var body io.Reader = recorder.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				reader, err := gzip.NewReader(recorder.Body)
+				if err != nil {
+					t.Fatalf("invalid gzip body: %v", err)
+				}
+				body = reader
+			case "deflate":
+				body = flate.NewReader(recorder.Body)
+			}
+			decoded, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("failed to read the body: %v", err)
+			}
+
+			if tt.path == "/large" {
+				if recorder.Code != http.StatusCreated {
+					t.Fatalf("invalid status code: got %d, want 201", recorder.Code)
+				}
+				if !bytes.Contains(decoded, []byte(large)) {
+					t.Fatalf("invalid body: got %q", decoded)
+				}
+				if tt.wantEncoding != "" && recorder.Body.Len() >= len(decoded) {
+					t.Fatalf("body not compressed: got %d bytes for %d", recorder.Body.Len(), len(decoded))
+				}
+			}
+		})
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"gzip":                "gzip",
+		"deflate":             "deflate",
+		"br, deflate;q=0.8":   "deflate",
+		"GZIP;Q=0.1, deflate": "deflate",
+		"*;q=0.5, gzip;q=0":   "deflate",
+		"identity":            "",
+		"gzip;q=invalid":      "",
+	}
+
+	for acceptEncoding, want := range tests {
+		if got := negotiateEncoding(acceptEncoding); got != want {
+			t.Errorf("negotiateEncoding(%q): got %q, want %q", acceptEncoding, got, want)
+		}
+	}
+}
+
+func TestCompress_etag(t *testing.T) {
+	large := strings.Repeat("toko buku ", 200)
+	handler := Compress(CompressOptions{MinSize: 1024, Level: flate.DefaultCompression})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", r.URL.Query().Get("etag"))
+		w.Write([]byte(large))
+	}))
+
+	tests := []struct {
+		name           string
+		etag           string
+		acceptEncoding string
+		want           string
+	}{
+		{"gzip", `"0123456789abcdef"`, "gzip", `"0123456789abcdef-gzip"`},
+		{"deflate", `"0123456789abcdef"`, "deflate", `"0123456789abcdef-deflate"`},
+		{"identity", `"0123456789abcdef"`, "", `"0123456789abcdef"`},
+		{"weak", `W/"0123456789abcdef"`, "gzip", `W/"0123456789abcdef"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/?etag="+url.QueryEscape(tt.etag), nil)
+			if tt.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if got := recorder.Header().Get("ETag"); got != tt.want {
+				t.Fatalf("invalid ETag: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,7 @@ package web
 import (
 	"context"
 	"net/http"
-
-	"github.com/google/uuid"
+	"toko-buku-api/pkg/auth"
 )
 
 type ctxKey int
@@ -12,17 +11,18 @@ type ctxKey int
 const (
 	writerKey ctxKey = iota + 1
 	traceIDKey
+	claimsKey
 )
 
-func setTraceID(ctx context.Context, traceID uuid.UUID) context.Context {
+func setTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, traceIDKey, traceID)
 }
 
 // GetTraceID returns the traceID for the request.
-func GetTraceID(ctx context.Context) uuid.UUID {
-	v, ok := ctx.Value(traceIDKey).(uuid.UUID)
+func GetTraceID(ctx context.Context) string {
+	v, ok := ctx.Value(traceIDKey).(string)
 	if !ok {
-		return uuid.UUID{}
+		return ""
 	}
 
 	return v
@@ -41,3 +41,14 @@ func GetWriter(ctx context.Context) http.ResponseWriter {
 
 	return v
 }
+
+func setClaims(ctx context.Context, claims auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// GetClaims returns the claims of the authenticated request, ok false for
+// anonymous ones.
+func GetClaims(ctx context.Context) (auth.Claims, bool) {
+	v, ok := ctx.Value(claimsKey).(auth.Claims)
+	return v, ok
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the cross-origin requests the api answers.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed, * allowing any
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed, * allowing any
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers browsers let scripts read
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// Router finds the handler and pattern of a request, like http.ServeMux.
+type Router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// probedMethods are the methods an OPTIONS request is answered with when a
+// pattern of the router matches them.
+var probedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// CORS answers the OPTIONS requests of every path the router has patterns
+// for with the methods of those patterns, as a preflight when the origin is
+// allowed, and adds the CORS headers to the responses to allowed origins.
+// OPTIONS requests of unknown paths go on to the router.
+func CORS(options CORSOptions, router Router) Middleware {
+	anyOrigin := slices.Contains(options.AllowedOrigins, "*")
+	anyHeader := slices.Contains(options.AllowedHeaders, "*")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && (anyOrigin || slices.ContainsFunc(options.AllowedOrigins, func(o string) bool {
+				return strings.EqualFold(o, origin)
+			}))
+
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			if allowed {
+				// the wildcard is refused with credentials, the origin is not
+				if anyOrigin && !options.AllowCredentials {
+					header.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					header.Set("Access-Control-Allow-Origin", origin)
+				}
+				if options.AllowCredentials {
+					header.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			if r.Method != http.MethodOptions {
+				if allowed && len(options.ExposedHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(options.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			methods := routeMethods(router, r)
+			if len(methods) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			header.Set("Allow", strings.Join(append(methods, http.MethodOptions), ", "))
+
+			requestMethod := r.Header.Get("Access-Control-Request-Method")
+			if allowed && requestMethod != "" {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+
+				allowedMethods := slices.DeleteFunc(methods, func(m string) bool {
+					return !slices.Contains(options.AllowedMethods, m)
+				})
+				header.Set("Access-Control-Allow-Methods", strings.Join(allowedMethods, ", "))
+
+				if requestHeaders := r.Header.Get("Access-Control-Request-Headers"); anyHeader && requestHeaders != "" {
+					header.Set("Access-Control-Allow-Headers", requestHeaders)
+				} else if len(options.AllowedHeaders) > 0 && !anyHeader {
+					header.Set("Access-Control-Allow-Headers", strings.Join(options.AllowedHeaders, ", "))
+				}
+				if options.MaxAge > 0 {
+					header.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+				}
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// routeMethods returns the methods the router has a pattern for at the path
+// of r.
+func routeMethods(router Router, r *http.Request) []string {
+	var methods []string
+	for _, method := range probedMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := router.Handler(probe); pattern != "" {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
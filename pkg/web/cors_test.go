@@ -0,0 +1,123 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	mux.HandleFunc("GET /authors", ok)
+	mux.HandleFunc("POST /authors", ok)
+	mux.HandleFunc("GET /authors/{authorById}", ok)
+	mux.HandleFunc("DELETE /authors/{authorById}", ok)
+
+	options := CORSOptions{
+		AllowedOrigins:   []string{"https://toko.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	handler := CORS(options, mux)(mux)
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		header      http.Header
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name: "preflight", method: http.MethodOptions, path: "/authors",
+			header:     http.Header{"Origin": {"https://toko.example.com"}, "Access-Control-Request-Method": {"POST"}},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://toko.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST",
+				"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+				"Access-Control-Max-Age":           "600",
+				"Allow":                            "GET, HEAD, POST, OPTIONS",
+			},
+		},
+		{
+			name: "preflight of a pattern with a wildcard", method: http.MethodOptions, path: "/authors/1",
+			header:      http.Header{"Origin": {"https://toko.example.com"}, "Access-Control-Request-Method": {"DELETE"}},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Methods": "GET", "Allow": "GET, HEAD, DELETE, OPTIONS"},
+		},
+		{
+			name: "preflight of another origin", method: http.MethodOptions, path: "/authors",
+			header:      http.Header{"Origin": {"https://evil.example.com"}, "Access-Control-Request-Method": {"POST"}},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name: "options without origin", method: http.MethodOptions, path: "/authors",
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Allow": "GET, HEAD, POST, OPTIONS", "Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "options of an unknown path", method: http.MethodOptions, path: "/unknown",
+			header:     http.Header{"Origin": {"https://toko.example.com"}, "Access-Control-Request-Method": {"GET"}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "request", method: http.MethodGet, path: "/authors",
+			header:     http.Header{"Origin": {"https://toko.example.com"}},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   "https://toko.example.com",
+				"Access-Control-Expose-Headers": "X-Request-ID",
+				"Vary":                          "Origin",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(tc.method, tc.path, nil)
+			for key, values := range tc.header {
+				request.Header[key] = values
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.wantStatus {
+				t.Fatalf("invalid status: got %d, want %d", recorder.Code, tc.wantStatus)
+			}
+			for key, want := range tc.wantHeaders {
+				if got := recorder.Header().Get(key); got != want {
+					t.Fatalf("invalid %s: got %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORS_anyOrigin(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /books", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, credentials := range []bool{false, true} {
+		options := CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: credentials}
+		request := httptest.NewRequest(http.MethodGet, "/books", nil)
+		request.Header.Set("Origin", "https://toko.example.com")
+		recorder := httptest.NewRecorder()
+		CORS(options, mux)(mux).ServeHTTP(recorder, request)
+
+		want := "*"
+		if credentials {
+			want = "https://toko.example.com"
+		}
+		if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Fatalf("invalid allowed origin with credentials %v: got %q, want %q", credentials, got, want)
+		}
+	}
+}
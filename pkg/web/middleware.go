@@ -0,0 +1,16 @@
+package web
+
+import "net/http"
+
+// Middleware runs code before and after a handler, for every route.
+type Middleware func(next http.Handler) http.Handler
+
+// Wrap returns handler wrapped by the middlewares, the first one running
+// first.
+func Wrap(handler http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+
+	return handler
+}
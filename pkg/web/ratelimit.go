@@ -0,0 +1,116 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/ratelimit"
+	"toko-buku-api/utils"
+)
+
+// DefaultRateLimitGroup is the group whose limit applies to the route groups
+// without their own.
+const DefaultRateLimitGroup = "default"
+
+// RateLimit limits the requests of every client per route group, the first
+// segment of the path, with the limit of the group or of the default group,
+// every group counting its requests apart.
+// Authenticated clients are told apart by their principal, anonymous ones
+// by their ip. Requests over the limit are answered with 429; a failing
+// store lets them through.
+func RateLimit(log *logger.Logger, store ratelimit.Store, limits map[string]ratelimit.Limit) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			group := routeGroup(r.URL.Path)
+			limit, ok := limits[group]
+			if !ok {
+				limit = limits[DefaultRateLimitGroup]
+			}
+			if limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := "ip:" + ClientIP(r)
+			if claims, ok := GetClaims(r.Context()); ok {
+				client = "sub:" + claims.Subject
+			}
+
+			result, err := store.Take(r.Context(), group+"|"+client, limit, time.Now())
+			if err != nil {
+				log.Error(r.Context(), "failed to take a rate limit token", "error", err, "group", group)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			if !result.Allowed {
+				header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				message := "rate limit exceeded"
+				RespondError(w, r, http.StatusTooManyRequests, message, utils.StatusTooManyRequests(message))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// failedAuthGroup is the group counting the failed authentications of every
+// ip, apart from the route groups.
+const failedAuthGroup = "failed-auth"
+
+// LimitFailedAuth limits the failed authentications of every ip, to slow
+// down the guessing of tokens and API keys. It goes before Authenticate:
+// every request carrying credentials answered with 401 takes a token, and
+// once none is left, the requests carrying credentials are answered with
+// 429 before being authenticated. Valid credentials take no token. A
+// failing store lets the requests through.
+func LimitFailedAuth(log *logger.Logger, store ratelimit.Store, limit ratelimit.Limit) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limit.Unlimited() || r.Header.Get("Authorization") == "" && r.Header.Get(HeaderAPIKey) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := failedAuthGroup + "|ip:" + ClientIP(r)
+			result, err := store.Peek(r.Context(), key, limit, time.Now())
+			if err != nil {
+				log.Error(r.Context(), "failed to peek a rate limit token", "error", err, "group", failedAuthGroup)
+			} else if !result.Allowed {
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				message := "too many failed authentications"
+				RespondError(w, r, http.StatusTooManyRequests, message, utils.StatusTooManyRequests(message))
+				return
+			}
+
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, r)
+			if rw.Status() != http.StatusUnauthorized {
+				return
+			}
+			if _, err := store.Take(r.Context(), key, limit, time.Now()); err != nil {
+				log.Error(r.Context(), "failed to take a rate limit token", "error", err, "group", failedAuthGroup)
+			}
+		})
+	}
+}
+
+// routeGroup returns the first segment of the path, like authors for
+// /authors/1.
+func routeGroup(path string) string {
+	group, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return group
+}
+
+// ceilSeconds formats d as whole seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
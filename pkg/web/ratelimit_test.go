@@ -0,0 +1,111 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", nil)
+	limits := map[string]ratelimit.Limit{
+		DefaultRateLimitGroup: ratelimit.PerMinute(60, 1),
+		"authors":             ratelimit.PerMinute(60, 2),
+		"orders":              {},
+	}
+	handler := RateLimit(log, ratelimit.NewMemoryStore(), limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	steps := []struct {
+		path       string
+		ip         string
+		subject    string
+		wantStatus int
+		wantLimit  string
+	}{
+		{"/authors", "10.0.0.1:1234", "", http.StatusOK, "2"},
+		{"/authors/1", "10.0.0.1:1234", "", http.StatusOK, "2"},
+		{"/authors", "10.0.0.1:1234", "", http.StatusTooManyRequests, "2"},
+		{"/authors", "10.0.0.2:1234", "", http.StatusOK, "2"},
+		{"/authors", "10.0.0.1:1234", "apikey:1234", http.StatusOK, "2"},
+		{"/books", "10.0.0.1:1234", "", http.StatusOK, "1"},
+		{"/types", "10.0.0.1:1234", "", http.StatusOK, "1"},
+		{"/orders", "10.0.0.1:1234", "", http.StatusOK, ""},
+		{"/orders", "10.0.0.1:1234", "", http.StatusOK, ""},
+	}
+
+	for i, step := range steps {
+		request := httptest.NewRequest(http.MethodGet, step.path, nil)
+		request.RemoteAddr = step.ip
+		if step.subject != "" {
+			request = request.WithContext(setClaims(request.Context(), auth.Claims{Subject: step.subject}))
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != step.wantStatus || recorder.Header().Get("RateLimit-Limit") != step.wantLimit {
+			t.Fatalf("step %d: invalid response: got %d %v, want %d with limit %q", i, recorder.Code, recorder.Header(), step.wantStatus, step.wantLimit)
+		}
+		if step.wantStatus == http.StatusTooManyRequests && recorder.Header().Get("Retry-After") != "1" {
+			t.Fatalf("step %d: invalid Retry-After: got %q, want 1", i, recorder.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestLimitFailedAuth(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", nil)
+	handler := LimitFailedAuth(log, ratelimit.NewMemoryStore(), ratelimit.PerMinute(60, 2))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get(HeaderAPIKey) {
+		case "bad":
+			unauthorized(w, r, "invalid api key")
+		case "down":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	steps := []struct {
+		ip         string
+		key        string
+		wantStatus int
+	}{
+		{"10.0.0.1:1234", "good", http.StatusOK},
+		{"10.0.0.1:1234", "bad", http.StatusUnauthorized},
+		{"10.0.0.1:1234", "good", http.StatusOK},
+		{"10.0.0.1:1234", "bad", http.StatusUnauthorized},
+		{"10.0.0.1:1234", "bad", http.StatusTooManyRequests},
+		{"10.0.0.1:1234", "good", http.StatusTooManyRequests},
+		{"10.0.0.1:1234", "", http.StatusOK},
+		{"10.0.0.2:1234", "bad", http.StatusUnauthorized},
+		{"10.0.0.3:1234", "down", http.StatusInternalServerError},
+		{"10.0.0.3:1234", "down", http.StatusInternalServerError},
+		{"10.0.0.3:1234", "down", http.StatusInternalServerError},
+		{"10.0.0.3:1234", "bad", http.StatusUnauthorized},
+	}
+
+	for i, step := range steps {
+		request := httptest.NewRequest(http.MethodGet, "/authors", nil)
+		request.RemoteAddr = step.ip
+		if step.key != "" {
+			request.Header.Set(HeaderAPIKey, step.key)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != step.wantStatus {
+			t.Fatalf("step %d: invalid status code: got %d, want %d", i, recorder.Code, step.wantStatus)
+		}
+		if step.wantStatus == http.StatusTooManyRequests && recorder.Header().Get("Retry-After") != "1" {
+			t.Fatalf("step %d: invalid Retry-After: got %q, want 1", i, recorder.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestCeilSeconds(t *testing.T) {
+	if got := ceilSeconds(1500 * time.Millisecond); got != "2" {
+		t.Fatalf("invalid seconds: got %s, want 2", got)
+	}
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/utils"
+)
+
+// Recover turns a panic of the handler into the standard unhandled error
+// response, logging the panic and its stack at Error level so the Error
+// event of log fires. Details of the panic never reach the client.
+func Recover(log *logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := newResponseWriter(w)
+
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				log.Error(r.Context(), "panic recovered",
+					"panic", fmt.Sprint(p),
+					"stack", string(debug.Stack()),
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+
+				// the status line is gone once the handler wrote it
+				if rw.status != 0 {
+					return
+				}
+				response := utils.UnhandledError[string]()
+				RespondError(rw, r, http.StatusInternalServerError, response.Message, response)
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"toko-buku-api/pkg/logger"
+)
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	var events []logger.Record
+	log := logger.NewWithEvents(&buf, logger.LevelDebug, "TEST", GetTraceID, logger.Events{
+		Error: func(ctx context.Context, r logger.Record) {
+			events = append(events, r)
+		},
+	})
+
+	handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("secret dsn root:rahasia")
+	}), Trace, Recover(log))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/books", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("invalid status code: got %d, want 500", recorder.Code)
+	}
+	if strings.Contains(recorder.Body.String(), "rahasia") {
+		t.Fatalf("invalid body: leaks the panic %s", recorder.Body.String())
+	}
+
+	var body struct {
+		Status  int
+		Message string
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body.Status != http.StatusInternalServerError || body.Message != "Unhandled error occurred. Please try again later" {
+		t.Fatalf("invalid body: got %+v", body)
+	}
+
+	if len(events) != 1 || events[0].Attributes["panic"] != "secret dsn root:rahasia" {
+		t.Fatalf("invalid error events: got %+v", events)
+	}
+	if stack, _ := events[0].Attributes["stack"].(string); !strings.Contains(stack, "recover_test.go") {
+		t.Fatalf("invalid stack: got %q", stack)
+	}
+}
+
+func TestRecover_abort(t *testing.T) {
+	log := logger.New(&bytes.Buffer{}, logger.LevelDebug, "TEST", nil)
+	handler := Recover(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Fatalf("invalid recover: want http.ErrAbortHandler re-panicked")
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books", nil))
+}
@@ -0,0 +1,30 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"toko-buku-api/utils"
+)
+
+// AcceptsProblem reports whether the client asked for problem details.
+func AcceptsProblem(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, utils.ContentTypeProblem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RespondError answers with the RFC 7807 problem details of status and
+// message the clients accepting application/problem+json, and with response,
+// the BaseResponseModel envelope, the others.
+func RespondError(w http.ResponseWriter, r *http.Request, status int, message string, response any) {
+	if AcceptsProblem(r) {
+		utils.RespondWithProblem(w, utils.NewProblem(status, message, r.URL.Path))
+		return
+	}
+
+	utils.RespondErrorWithJSON(w, status, response)
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"toko-buku-api/pkg/auth"
+	"toko-buku-api/pkg/logger"
+	"toko-buku-api/pkg/ratelimit"
+	"toko-buku-api/utils"
+)
+
+func TestRespondError_problem(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelError, "TEST", nil)
+	verifier := fakeKeyVerifier{"tbk_1234_secret": {Subject: "apikey:1234", Scopes: []string{"countries:write"}}}
+	policy := auth.Policy{}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// the first request takes the only token of the client
+	rateLimit := RateLimit(log, ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{DefaultRateLimitGroup: ratelimit.PerMinute(1, 1)})
+	rateLimit(ok).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/countries", nil))
+
+	tests := []struct {
+		name       string
+		handler    http.Handler
+		key        string
+		wantStatus int
+		wantDetail string
+	}{
+		{"invalid credentials", Wrap(ok, Authenticate(APIKey(verifier))), "tbk_0000_secret", http.StatusUnauthorized, "invalid api key"},
+		{"anonymous", Wrap(ok, Authorize(policy, auth.CountriesWrite)), "", http.StatusUnauthorized, "authentication required"},
+		{"forbidden", Wrap(ok, Authenticate(APIKey(verifier)), Authorize(policy, auth.CountriesDelete)), "tbk_1234_secret", http.StatusForbidden, "countries:delete permission required"},
+		{"rate limited", Wrap(ok, rateLimit), "", http.StatusTooManyRequests, "rate limit exceeded"},
+		{"panic", Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("boom") }), Recover(log)), "", http.StatusInternalServerError, "Unhandled error occurred. Please try again later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, accept := range []string{utils.ContentTypeProblem, ""} {
+				request := httptest.NewRequest(http.MethodPost, "/countries", nil)
+				request.Header.Set("Accept", accept)
+				if tt.key != "" {
+					request.Header.Set(HeaderAPIKey, tt.key)
+				}
+				recorder := httptest.NewRecorder()
+				tt.handler.ServeHTTP(recorder, request)
+
+				if recorder.Code != tt.wantStatus {
+					t.Fatalf("invalid status with Accept %q: got %d, want %d", accept, recorder.Code, tt.wantStatus)
+				}
+				if accept == "" {
+					if contentType := recorder.Header().Get("Content-Type"); contentType == utils.ContentTypeProblem {
+						t.Fatalf("invalid Content-Type without Accept: got %q", contentType)
+					}
+					continue
+				}
+
+				var problem utils.ProblemDetails
+				if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("failed to decode the problem: %v", err)
+				}
+				if recorder.Header().Get("Content-Type") != utils.ContentTypeProblem || problem.Status != tt.wantStatus || problem.Detail != tt.wantDetail || problem.Instance != "/countries" {
+					t.Fatalf("invalid problem: got %q %+v", recorder.Header().Get("Content-Type"), problem)
+				}
+			}
+		})
+	}
+}
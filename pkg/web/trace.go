@@ -0,0 +1,75 @@
+package web
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Headers carrying the trace id of a request.
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceparent = "traceparent"
+)
+
+// maxRequestIDLength bounds the X-Request-ID values kept from clients.
+const maxRequestIDLength = 128
+
+// Trace assigns the request a trace id, kept from the X-Request-ID or the
+// W3C traceparent header when the client sent a valid one, and echoes it in
+// the X-Request-ID response header.
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID := requestID(r.Header.Get(HeaderRequestID))
+		if traceID == "" {
+			traceID = traceparentID(r.Header.Get(HeaderTraceparent))
+		}
+		if traceID == "" {
+			traceID = uuid.NewString()
+		}
+
+		w.Header().Set(HeaderRequestID, traceID)
+
+		ctx := setTraceID(r.Context(), traceID)
+		ctx = setWriter(ctx, w)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestID returns the X-Request-ID value when it is short printable ASCII,
+// so it is safe to log and echo.
+func requestID(value string) string {
+	if len(value) > maxRequestIDLength {
+		return ""
+	}
+
+	for _, c := range value {
+		if c <= ' ' || c > '~' {
+			return ""
+		}
+	}
+
+	return value
+}
+
+// traceparentID returns the trace id of a version 00 traceparent header, in
+// the form 00-<32 hex trace id>-<16 hex parent id>-<2 hex flags>.
+func traceparentID(value string) string {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ""
+	}
+
+	traceID := parts[1]
+	if _, err := hex.DecodeString(traceID); err != nil || traceID != strings.ToLower(traceID) {
+		return ""
+	}
+	if strings.Trim(traceID, "0") == "" {
+		return ""
+	}
+
+	return traceID
+}
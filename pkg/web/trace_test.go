@@ -0,0 +1,77 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTrace(t *testing.T) {
+	testCases := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{"request id", http.Header{"X-Request-Id": {"req-42"}}, "req-42"},
+		{"traceparent", http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"request id before traceparent", http.Header{"X-Request-Id": {"req-42"}, "Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}, "req-42"},
+		{"invalid request id", http.Header{"X-Request-Id": {"req 42\n"}}, ""},
+		{"zero traceparent", http.Header{"Traceparent": {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}}, ""},
+		{"none", nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var traceID string
+			handler := Trace(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				traceID = GetTraceID(r.Context())
+				if GetWriter(r.Context()) == nil {
+					t.Fatalf("invalid writer: got nil")
+				}
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/books", nil)
+			request.Header = tc.header
+			if request.Header == nil {
+				request.Header = http.Header{}
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if tc.want == "" {
+				if _, err := uuid.Parse(traceID); err != nil {
+					t.Fatalf("invalid generated trace id %q: %v", traceID, err)
+				}
+			} else if traceID != tc.want {
+				t.Fatalf("invalid trace id: got %q, want %q", traceID, tc.want)
+			}
+
+			if echoed := recorder.Header().Get(HeaderRequestID); echoed != traceID {
+				t.Fatalf("invalid echoed trace id: got %q, want %q", echoed, traceID)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}), mw("first"), mw("second"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "handler" {
+		t.Fatalf("invalid order: got %v", order)
+	}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// ContentTypeProblem is the media type of ProblemDetails, RFC 7807
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemDetails helpers, RFC 7807 error response
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError helpers, the failed validation of a request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// returns a problem without a more specific type than its http status
+func NewProblem(status int, detail, instance string) ProblemDetails {
+	return ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+	}
+}
+
+func RespondWithProblem(writer http.ResponseWriter, problem ProblemDetails) {
+	if problem.Status >= http.StatusInternalServerError {
+		// HTTP status: 500
+		log.Printf("Responding with 5xx problem: %v", problem)
+	}
+
+	payloadJSON, err := json.Marshal(problem)
+	if err != nil {
+		log.Printf("Failed to marshal JSON problem: %v", err)
+		// HTTP status: 500
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", ContentTypeProblem)
+	writer.WriteHeader(problem.Status)
+	writer.Write(payloadJSON)
+}
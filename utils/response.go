@@ -12,6 +12,16 @@ type BaseResponseModel[T any] struct {
 type BaseResponseDataModel[T any] struct {
 	BaseResponseModel[T]
 	Data T `json:"data,omitempty"`
+	*PageModel
+}
+
+// PageModel helpers, pagination of list responses
+type PageModel struct {
+	Total      int               `json:"total"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset,omitempty"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Links      map[string]string `json:"links,omitempty"`
 }
 
 // BaseResponseErrorModel helpers
@@ -53,6 +63,13 @@ func StatusOK[T any](data T) BaseResponseDataModel[T] {
 	}
 }
 
+// returns http 200 OK with pagination
+func StatusOKWithPage[T any](data T, page *PageModel) BaseResponseDataModel[T] {
+	response := StatusOK(data)
+	response.PageModel = page
+	return response
+}
+
 // returns http 400
 func StatusBadRequest[T string]() BaseResponseModel[T] {
 	return BaseResponseModel[T]{
@@ -61,6 +78,14 @@ func StatusBadRequest[T string]() BaseResponseModel[T] {
 	}
 }
 
+// returns http 400 with the reason
+func StatusBadRequestWithMessage[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusBadRequest,
+		Message: message,
+	}
+}
+
 // returns http 401
 func StatusUnauthorized[T string](message string) BaseResponseModel[T] {
 	return BaseResponseModel[T]{
@@ -69,6 +94,54 @@ func StatusUnauthorized[T string](message string) BaseResponseModel[T] {
 	}
 }
 
+// returns http 403
+func StatusForbidden[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusForbidden,
+		Message: message,
+	}
+}
+
+// returns http 412
+func StatusPreconditionFailed[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusPreconditionFailed,
+		Message: message,
+	}
+}
+
+// returns http 413
+func StatusRequestEntityTooLarge[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusRequestEntityTooLarge,
+		Message: message,
+	}
+}
+
+// returns http 415
+func StatusUnsupportedMediaType[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusUnsupportedMediaType,
+		Message: message,
+	}
+}
+
+// returns http 428
+func StatusPreconditionRequired[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusPreconditionRequired,
+		Message: message,
+	}
+}
+
+// returns http 429
+func StatusTooManyRequests[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusTooManyRequests,
+		Message: message,
+	}
+}
+
 // returns http 500
 func UnhandledError[T any]() BaseResponseModel[T] {
 	return BaseResponseModel[T]{
@@ -92,3 +165,19 @@ func StatusNotFound[T string]() BaseResponseModel[T] {
 		Message: "Not Found",
 	}
 }
+
+// returns http 409
+func StatusConflict[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusConflict,
+		Message: message,
+	}
+}
+
+// returns http 422
+func StatusUnprocessableEntity[T string](message string) BaseResponseModel[T] {
+	return BaseResponseModel[T]{
+		Status:  http.StatusUnprocessableEntity,
+		Message: message,
+	}
+}